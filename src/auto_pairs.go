@@ -0,0 +1,125 @@
+package main
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// GetAutoPairs returns the character pairs that are closed automatically in the buffer
+func (buffer *Buffer) GetAutoPairs() [][2]rune {
+	if !Config.AutoClosePairs {
+		return nil
+	}
+
+	pairStrings, ok := Config.AutoPairs["default"]
+	if language := buffer.GetLanguage(); language != nil {
+		if languagePairs, ok := Config.AutoPairs[language.Name]; ok {
+			pairStrings = languagePairs
+		}
+	} else if !ok {
+		return nil
+	}
+
+	pairs := make([][2]rune, 0, len(pairStrings))
+	for _, pairStr := range pairStrings {
+		runes := []rune(pairStr)
+		if len(runes) != 2 {
+			continue
+		}
+
+		pairs = append(pairs, [2]rune{runes[0], runes[1]})
+	}
+
+	return pairs
+}
+
+// insertAutoPair handles typing r at the cursor when it is part of an auto-closed pair.
+// It returns false if r should be inserted normally
+func (window *Window) insertAutoPair(r rune) bool {
+	buffer := window.CurrentBuffer
+	str := buffer.Contents
+	index := buffer.CursorPos
+
+	for _, pair := range buffer.GetAutoPairs() {
+		openStr, closeStr := string(pair[0]), string(pair[1])
+
+		// Wrap selection with pair
+		if r == pair[0] && buffer.Selection != nil {
+			edge1, edge2 := buffer.GetSelectionEdges()
+			if edge2 == len(str) {
+				edge2 = len(str) - 1
+			}
+
+			buffer.SetContents(str[:edge1] + openStr + str[edge1:edge2+1] + closeStr + str[edge2+1:])
+			buffer.Selection = &Selection{
+				selectionStart: edge1 + len(openStr),
+				selectionEnd:   edge2 + len(openStr),
+			}
+			window.SetCursorPos(edge2 + len(openStr))
+			return true
+		}
+
+		next, _ := utf8.DecodeRuneInString(str[index:])
+		prev, _ := utf8.DecodeLastRuneInString(str[:index])
+
+		// Step over closing character
+		if r == pair[1] && index < len(str) && next == r {
+			window.SetCursorPos(index + len(closeStr))
+			return true
+		}
+
+		if r != pair[0] {
+			continue
+		}
+
+		// Only close pair if it is not followed by a word
+		if index < len(str) && !unicode.IsSpace(next) && !isClosingPairChar(buffer, next) {
+			return false
+		}
+
+		// Do not close quotes directly following a word
+		if pair[0] == pair[1] && index > 0 && (unicode.IsLetter(prev) || unicode.IsDigit(prev) || prev == '_') {
+			return false
+		}
+
+		buffer.SetContents(str[:index] + openStr + closeStr + str[index:])
+		window.SetCursorPos(index + len(openStr))
+		return true
+	}
+
+	return false
+}
+
+// deleteAutoPair removes both characters of an empty pair surrounding the cursor.
+// It returns false if nothing was removed
+func (window *Window) deleteAutoPair() bool {
+	buffer := window.CurrentBuffer
+	str := buffer.Contents
+	index := buffer.CursorPos
+
+	if buffer.Selection != nil || index == 0 || index >= len(str) {
+		return false
+	}
+
+	prev, prevSize := utf8.DecodeLastRuneInString(str[:index])
+	next, nextSize := utf8.DecodeRuneInString(str[index:])
+
+	for _, pair := range buffer.GetAutoPairs() {
+		if prev == pair[0] && next == pair[1] {
+			buffer.SetContents(str[:index-prevSize] + str[index+nextSize:])
+			window.SetCursorPos(index - prevSize)
+			return true
+		}
+	}
+
+	return false
+}
+
+func isClosingPairChar(buffer *Buffer, r rune) bool {
+	for _, pair := range buffer.GetAutoPairs() {
+		if r == pair[1] {
+			return true
+		}
+	}
+	return false
+}
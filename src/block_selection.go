@@ -0,0 +1,306 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+type BlockSelection struct {
+	anchorLine, anchorCol int
+	cursorLine, cursorCol int
+}
+
+// GetEdges returns the top line, left column, bottom line and right column of the block. The right column is exclusive
+func (block *BlockSelection) GetEdges() (int, int, int, int) {
+	return min(block.anchorLine, block.cursorLine), min(block.anchorCol, block.cursorCol),
+		max(block.anchorLine, block.cursorLine), max(block.anchorCol, block.cursorCol)
+}
+
+func getRuneDisplayWidth(r rune) int {
+	if r == '\t' {
+		return Config.TabIndentation
+	}
+	return 1
+}
+
+// DisplayColumnToPos returns the position of the first character at or after the display column col in line,
+// along with the display column that was actually reached
+func (buffer *Buffer) DisplayColumnToPos(line, col int) (int, int) {
+	offsets := buffer.GetLineOffsets()
+	line = min(max(line, 0), len(offsets)-1)
+
+	pos := offsets[line]
+	x := 0
+	for pos < len(buffer.Contents) && buffer.Contents[pos] != '\n' && x < col {
+		r, size := utf8.DecodeRuneInString(buffer.Contents[pos:])
+		x += getRuneDisplayWidth(r)
+		pos += size
+	}
+
+	return pos, x
+}
+
+func (buffer *Buffer) PosToDisplayColumn(pos int) int {
+	pos = min(max(pos, 0), len(buffer.Contents))
+
+	lineStart := strings.LastIndexByte(buffer.Contents[:pos], '\n') + 1
+
+	x := 0
+	for _, r := range buffer.Contents[lineStart:pos] {
+		x += getRuneDisplayWidth(r)
+	}
+
+	return x
+}
+
+// expandTabAt replaces a tab on line that only partly covers the display column col with spaces, so col can be edited
+func (buffer *Buffer) expandTabAt(line, col int) {
+	offsets := buffer.GetLineOffsets()
+	if line < 0 || line >= len(offsets) {
+		return
+	}
+
+	pos := offsets[line]
+	x := 0
+	for pos < len(buffer.Contents) && buffer.Contents[pos] != '\n' && x < col {
+		r, size := utf8.DecodeRuneInString(buffer.Contents[pos:])
+		width := getRuneDisplayWidth(r)
+
+		if r == '\t' && x+width > col {
+			buffer.SetContents(buffer.Contents[:pos] + strings.Repeat(" ", width) + buffer.Contents[pos+size:])
+			return
+		}
+
+		x += width
+		pos += size
+	}
+}
+
+// expandBlockTabs replaces tabs crossing the left or right edge of the block selection with spaces
+func (buffer *Buffer) expandBlockTabs() {
+	top, left, bottom, right := buffer.BlockSelection.GetEdges()
+	for line := top; line <= bottom; line++ {
+		buffer.expandTabAt(line, left)
+		buffer.expandTabAt(line, right)
+	}
+}
+
+// getBlockLineRange returns the start and end positions of the text on line that overlaps the display columns
+// left to right, along with the display column at the start. Tabs crossing either edge are included, like in expandBlockTabs
+func (buffer *Buffer) getBlockLineRange(line, left, right int) (int, int, int) {
+	offsets := buffer.GetLineOffsets()
+	line = min(max(line, 0), len(offsets)-1)
+
+	start, startCol := -1, 0
+	pos := offsets[line]
+	x := 0
+	for pos < len(buffer.Contents) && buffer.Contents[pos] != '\n' && x < max(right, left+1) {
+		r, size := utf8.DecodeRuneInString(buffer.Contents[pos:])
+		width := getRuneDisplayWidth(r)
+
+		if start == -1 && (x >= left || (left != right && x+width > left)) {
+			start, startCol = pos, x
+		}
+		if x >= right && start != -1 {
+			break
+		}
+
+		x += width
+		pos += size
+	}
+
+	if start == -1 {
+		return pos, pos, x
+	}
+
+	return start, pos, startCol
+}
+
+// GetBlockRanges returns the start and end positions of the selected text on every line of the block selection
+func (buffer *Buffer) GetBlockRanges() [][2]int {
+	if buffer.BlockSelection == nil {
+		return nil
+	}
+
+	top, left, bottom, right := buffer.BlockSelection.GetEdges()
+
+	ranges := make([][2]int, 0, bottom-top+1)
+	for line := top; line <= bottom; line++ {
+		start, end, _ := buffer.getBlockLineRange(line, left, right)
+		ranges = append(ranges, [2]int{start, end})
+	}
+
+	return ranges
+}
+
+// GetBlockText returns the selected text of the block selection, one line per row.
+// Tabs crossing an edge of the block are copied as spaces for the columns inside it
+func (buffer *Buffer) GetBlockText() string {
+	if buffer.BlockSelection == nil {
+		return ""
+	}
+
+	top, left, bottom, right := buffer.BlockSelection.GetEdges()
+
+	lines := make([]string, 0, bottom-top+1)
+	for line := top; line <= bottom; line++ {
+		start, end, x := buffer.getBlockLineRange(line, left, right)
+
+		var text strings.Builder
+		for _, r := range buffer.Contents[start:end] {
+			width := getRuneDisplayWidth(r)
+			if x >= left && x+width <= right {
+				text.WriteRune(r)
+			} else {
+				text.WriteString(strings.Repeat(" ", min(x+width, right)-max(x, left)))
+			}
+			x += width
+		}
+
+		lines = append(lines, text.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (window *Window) StartBlockSelection(line, col int) {
+	buffer := window.CurrentBuffer
+
+	buffer.Selection = nil
+	buffer.CollapseCursors()
+	buffer.BlockSelection = &BlockSelection{
+		anchorLine: line,
+		anchorCol:  col,
+		cursorLine: line,
+		cursorCol:  col,
+	}
+
+	window.syncBlockSelectionCursor()
+}
+
+func (window *Window) ExtendBlockSelection(dx, dy int) {
+	buffer := window.CurrentBuffer
+
+	if buffer.BlockSelection == nil {
+		_, y := window.GetCursorPos2D()
+		window.StartBlockSelection(y, buffer.PosToDisplayColumn(buffer.CursorPos))
+	}
+
+	lines := len(buffer.GetLineOffsets())
+	buffer.BlockSelection.cursorLine = min(max(buffer.BlockSelection.cursorLine+dy, 0), lines-1)
+	buffer.BlockSelection.cursorCol = max(buffer.BlockSelection.cursorCol+dx, 0)
+
+	window.syncBlockSelectionCursor()
+}
+
+func (window *Window) SetBlockSelectionCursor(line, col int) {
+	buffer := window.CurrentBuffer
+
+	if buffer.BlockSelection == nil {
+		return
+	}
+
+	lines := len(buffer.GetLineOffsets())
+	buffer.BlockSelection.cursorLine = min(max(line, 0), lines-1)
+	buffer.BlockSelection.cursorCol = max(col, 0)
+
+	window.syncBlockSelectionCursor()
+}
+
+func (window *Window) syncBlockSelectionCursor() {
+	block := window.CurrentBuffer.BlockSelection
+
+	pos, _ := window.CurrentBuffer.DisplayColumnToPos(block.cursorLine, block.cursorCol)
+	window.SetCursorPos(pos)
+}
+
+// DeleteBlockText removes the selected text on every line of the block selection.
+// If the block is zero columns wide and backspace is set, the character before the block is removed instead
+func (window *Window) DeleteBlockText(backspace bool) {
+	buffer := window.CurrentBuffer
+	block := buffer.BlockSelection
+
+	if block == nil {
+		return
+	}
+
+	buffer.expandBlockTabs()
+
+	top, left, _, right := block.GetEdges()
+	ranges := buffer.GetBlockRanges()
+	offsets := buffer.GetLineOffsets()
+
+	// Remove text from the bottom line upwards so earlier positions stay valid
+	col := left
+	for i := len(ranges) - 1; i >= 0; i-- {
+		start, end := ranges[i][0], ranges[i][1]
+
+		if left == right && backspace {
+			if start == offsets[top+i] {
+				continue
+			}
+
+			_, size := utf8.DecodeLastRuneInString(buffer.Contents[:start])
+			start -= size
+		}
+
+		// Collapse block selection to the start of the removed text on the cursor line
+		if top+i == block.cursorLine {
+			col = buffer.PosToDisplayColumn(start)
+		}
+
+		buffer.SetContents(buffer.Contents[:start] + buffer.Contents[end:])
+	}
+
+	block.anchorCol = col
+	block.cursorCol = col
+	window.syncBlockSelectionCursor()
+}
+
+// InsertBlockText replaces the selected text on every line of the block selection with text.
+// Each line of text goes into its own row. Lines are repeated if the block has more rows than text has lines,
+// and extra lines are dropped
+func (window *Window) InsertBlockText(text string) {
+	buffer := window.CurrentBuffer
+	block := buffer.BlockSelection
+
+	if block == nil {
+		return
+	}
+
+	top, left, bottom, right := block.GetEdges()
+	if left != right {
+		window.DeleteBlockText(false)
+	} else {
+		buffer.expandBlockTabs()
+	}
+
+	rows := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+	// Insert text from the bottom line upwards so earlier positions stay valid
+	col := left
+	for line := bottom; line >= top; line-- {
+		rowText := strings.TrimSuffix(rows[(line-top)%len(rows)], "\r")
+
+		pos, x := buffer.DisplayColumnToPos(line, left)
+
+		// Pad lines that are too short to reach the block
+		if x < left {
+			rowText = strings.Repeat(" ", left-x) + rowText
+		}
+
+		buffer.SetContents(buffer.Contents[:pos] + rowText + buffer.Contents[pos:])
+
+		// Move block selection after the inserted text on the cursor line
+		if line == block.cursorLine {
+			col = x
+			for _, r := range rowText {
+				col += getRuneDisplayWidth(r)
+			}
+		}
+	}
+
+	block.anchorCol = col
+	block.cursorCol = col
+	window.syncBlockSelectionCursor()
+}
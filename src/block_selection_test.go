@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// TestBlockSelectionTabEdges checks that tabs crossing either edge of a block are copied and deleted the same way
+func TestBlockSelectionTabEdges(t *testing.T) {
+	readConfig()
+	Config.TabIndentation = 4
+
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(80, 24)
+
+	tests := []struct {
+		name        string
+		left, right int
+		copied      string
+		deleted     string
+	}{
+		{"tab crossing left edge", 3, 6, "  b", "a  c"},
+		{"tab crossing right edge", 0, 2, "a ", "   bc"},
+		{"tab crossing both edges", 2, 4, "  ", "a  bc"},
+		{"tab inside block", 1, 5, "\t", "abc"},
+	}
+
+	for _, test := range tests {
+		buffer := &Buffer{Name: "test", canSave: true}
+		buffer.SetContents("a\tbc")
+		buffer.BlockSelection = &BlockSelection{
+			anchorLine: 0,
+			anchorCol:  test.left,
+			cursorLine: 0,
+			cursorCol:  test.right,
+		}
+		window := &Window{screen: screen, CurrentBuffer: buffer}
+
+		if copied := buffer.GetBlockText(); copied != test.copied {
+			t.Errorf("%s: expected copied text (%q), got (%q)", test.name, test.copied, copied)
+		}
+
+		window.DeleteBlockText(false)
+		if buffer.Contents != test.deleted {
+			t.Errorf("%s: expected contents (%q) after deleting, got (%q)", test.name, test.deleted, buffer.Contents)
+		}
+	}
+}
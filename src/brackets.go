@@ -0,0 +1,118 @@
+package main
+
+import (
+	"strings"
+)
+
+var bracketPairs = map[byte]byte{
+	'(': ')',
+	'[': ']',
+	'{': '}',
+}
+
+var quoteChars = []byte{'"', '\'', '`'}
+
+// FindMatchingBracket returns the position of the bracket or quote matching the one at pos, or -1 if there is none.
+// Brackets inside strings and comments are skipped if the buffer language is known
+func (buffer *Buffer) FindMatchingBracket(pos int) int {
+	if pos < 0 || pos >= len(buffer.Contents) {
+		return -1
+	}
+
+	c := buffer.Contents[pos]
+
+	// Only match brackets in the same string or comment as the one at pos, or in code if it is in code
+	_, regions := buffer.getCodeRegions()
+	skip := func(i int) bool {
+		return regions != nil && regions[i] != regions[pos]
+	}
+
+	for openChar, closeChar := range bracketPairs {
+		if c == openChar {
+			depth := 0
+			for i := pos + 1; i < len(buffer.Contents); i++ {
+				if skip(i) {
+					continue
+				}
+
+				if buffer.Contents[i] == openChar {
+					depth++
+				} else if buffer.Contents[i] == closeChar && depth == 0 {
+					return i
+				} else if buffer.Contents[i] == closeChar {
+					depth--
+				}
+			}
+		} else if c == closeChar {
+			depth := 0
+			for i := pos - 1; i >= 0; i-- {
+				if skip(i) {
+					continue
+				}
+
+				if buffer.Contents[i] == closeChar {
+					depth++
+				} else if buffer.Contents[i] == openChar && depth == 0 {
+					return i
+				} else if buffer.Contents[i] == openChar {
+					depth--
+				}
+			}
+		}
+	}
+
+	if strings.IndexByte(string(quoteChars), c) != -1 {
+		return buffer.findMatchingQuote(pos)
+	}
+
+	return -1
+}
+
+// bracketMatchKey holds what the bracket matching the one under the cursor depends on
+type bracketMatchKey struct {
+	revision, pos int
+}
+
+// getCursorBracketMatch returns the position of the bracket matching the one under the cursor, or -1 if there is none.
+// It is cached until the contents change or the cursor moves
+func (buffer *Buffer) getCursorBracketMatch() int {
+	key := bracketMatchKey{revision: buffer.revision, pos: buffer.CursorPos}
+	if !buffer.bracketMatchCached || buffer.bracketMatchKey != key {
+		buffer.bracketMatch = buffer.FindMatchingBracket(buffer.CursorPos)
+		buffer.bracketMatchKey = key
+		buffer.bracketMatchCached = true
+	}
+
+	return buffer.bracketMatch
+}
+
+// findMatchingQuote returns the position of the quote on the same line pairing up with the one at pos
+func (buffer *Buffer) findMatchingQuote(pos int) int {
+	c := buffer.Contents[pos]
+	lineStart, lineEnd := buffer.GetLineAt(pos)
+	mask := buffer.getCodeMask()
+
+	// Find unescaped quotes on the line
+	quotes := make([]int, 0)
+	for i := lineStart; i <= lineEnd && i < len(buffer.Contents); i++ {
+		if buffer.Contents[i] == '\\' {
+			i++
+		} else if buffer.Contents[i] == c && (mask == nil || mask[i]) {
+			quotes = append(quotes, i)
+		}
+	}
+
+	for i, quote := range quotes {
+		if quote != pos {
+			continue
+		}
+
+		if i%2 == 0 && i+1 < len(quotes) {
+			return quotes[i+1]
+		} else if i%2 == 1 {
+			return quotes[i-1]
+		}
+	}
+
+	return -1
+}
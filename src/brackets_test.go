@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestFindMatchingBracketRegions(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		pos      int
+		expected int
+	}{
+		{"code", "f(a, b)", 1, 6},
+		{"skips brackets in strings", "f(\")\", b)", 1, 8},
+		{"does not match across strings", "a(\"(\", \")\")", 3, -1},
+		{"does not match across comments", "// (\n// )\n", 3, -1},
+		{"matches inside comment", "// (a)\n", 3, 5},
+	}
+
+	for _, test := range tests {
+		buffer := &Buffer{filename: "test.go"}
+		buffer.SetContents(test.contents)
+
+		if match := buffer.FindMatchingBracket(test.pos); match != test.expected {
+			t.Errorf("%s: expected bracket at %d to match %d, got %d", test.name, test.pos, test.expected, match)
+		}
+	}
+}
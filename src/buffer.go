@@ -5,7 +5,10 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type Buffer struct {
@@ -17,8 +20,65 @@ type Buffer struct {
 
 	Selection *Selection
 
-	canSave  bool
-	filename string
+	ExtraCursors []*Cursor
+
+	BlockSelection *BlockSelection
+
+	canSave       bool
+	readOnly      bool
+	filename      string
+	savedContents string
+
+	// Incremented by SetContents. Caches of values derived from the contents are keyed on it
+	revision int
+
+	codeMask         []bool
+	codeRegions      []int
+	codeMaskRevision int
+
+	bracketMatch       int
+	bracketMatchKey    bracketMatchKey
+	bracketMatchCached bool
+
+	modifiedLines         []bool
+	modifiedLinesRevision int
+
+	searchLines         []int
+	searchLinesRevision int
+	searchLinesSearch   string
+
+	lineCount         int
+	lineCountRevision int
+
+	lineOffsets         []int
+	lineOffsetsRevision int
+
+	encoding         string
+	encodingRevision int
+
+	lineEnding         string
+	lineEndingRevision int
+
+	foldRanges         map[int]int
+	foldRangesRevision int
+
+	// First lines of closed folds, checked against the fold ranges of foldsRevision
+	folds         map[int]bool
+	foldsRevision int
+
+	// Incremented whenever folds are opened or closed
+	foldsVersion int
+
+	hiddenLines             []bool
+	hiddenLinesRevision     int
+	hiddenLinesFoldsVersion int
+
+	visualRows    []VisualRow
+	visualRowsKey visualRowsKey
+
+	// Signs shown in the line index, by group and line
+	signs      map[string]map[int]Sign
+	signGroups []string
 }
 
 type Selection struct {
@@ -53,20 +113,89 @@ func drawBuffer(window *Window) {
 
 	bufferX, bufferY, _, _ := window.GetTextAreaDimensions()
 
+	cursors := buffer.GetCursors()
+
+	// Get position of bracket matching the one under the cursor
+	matchingBracket := buffer.getCursorBracketMatch()
+
+	blockTop, blockLeft, blockBottom, blockRight := -1, -1, -1, -1
+	if buffer.BlockSelection != nil {
+		blockTop, blockLeft, blockBottom, blockRight = buffer.BlockSelection.GetEdges()
+	}
+
+	// Get positions where wrapped rows start
+	wrapPositions := make(map[int]bool)
+	if window.SoftWrap {
+		for _, row := range window.GetVisualRows() {
+			if !row.First {
+				wrapPositions[row.Start] = true
+			}
+		}
+	}
+
+	bufferStyle := CurrentStyle.GetStyle("buffer_area")
+	cursorStyle := CurrentStyle.GetStyle("cursor")
+
+	tabGlyph, _ := utf8.DecodeRuneInString(Config.WhitespaceGlyphs.Tab)
+	trailingSpaceGlyph, _ := utf8.DecodeRuneInString(Config.WhitespaceGlyphs.TrailingSpace)
+	lineEndingGlyph, _ := utf8.DecodeRuneInString(Config.WhitespaceGlyphs.LineEnding)
+	trailingSpacesStart := getTrailingSpacesStart(buffer.Contents, 0)
+
+	hiddenLines := buffer.GetHiddenLines()
+
+	line, col := 0, 0
 	for i, r := range buffer.Contents + " " {
+		// Skip lines hidden by folds
+		if line < len(hiddenLines) && hiddenLines[line] {
+			if r == '\n' {
+				trailingSpacesStart = getTrailingSpacesStart(buffer.Contents, i+1)
+				line++
+			}
+			continue
+		}
+
+		// Move wrapped characters to the next row
+		if wrapPositions[i] {
+			x = bufferX
+			y++
+		}
+
 		if x-buffer.OffsetX >= bufferX && y-buffer.OffsetY >= bufferY {
 			// Default style
-			style := tcell.StyleDefault.Background(CurrentStyle.BufferAreaBg).Foreground(CurrentStyle.BufferAreaFg)
+			style := bufferStyle
+
+			// Change background if matching bracket under cursor
+			if i == matchingBracket {
+				style = highlightStyle(style, CurrentStyle.BracketMatch)
+				_, _, attributes := style.Decompose()
+				style = style.Attributes(attributes | CurrentStyle.Attributes["bracket_match"])
+			}
+
+			underCursor := false
+			for _, cursor := range cursors {
+				if i == cursor.Pos {
+					underCursor = true
+				}
 
-			// Change background if under cursor
-			if i == buffer.CursorPos {
-				style = style.Background(CurrentStyle.BufferAreaSel)
+				// Change background if selected
+				if cursor.Selection != nil {
+					if edge1, edge2 := cursor.Selection.GetEdges(); i >= edge1 && i <= edge2 {
+						style = highlightStyle(style, CurrentStyle.BufferAreaSel)
+
+						// Show selection on entire tab space
+						if r == '\t' {
+							for j := 0; j < int(Config.TabIndentation); j++ {
+								window.screen.SetContent(x+j-buffer.OffsetX, y-buffer.OffsetY, r, nil, style)
+							}
+						}
+					}
+				}
 			}
 
-			// Change background if selected
-			if buffer.Selection != nil {
-				if edge1, edge2 := buffer.GetSelectionEdges(); i >= edge1 && i <= edge2 {
-					style = style.Background(CurrentStyle.BufferAreaSel)
+			// Change background if inside block selection
+			if line >= blockTop && line <= blockBottom && r != '\n' {
+				if (col < blockRight && col+getRuneDisplayWidth(r) > blockLeft) || (blockLeft == blockRight && col == blockLeft) {
+					style = highlightStyle(style, CurrentStyle.BufferAreaSel)
 
 					// Show selection on entire tab space
 					if r == '\t' {
@@ -77,19 +206,203 @@ func drawBuffer(window *Window) {
 				}
 			}
 
-			window.screen.SetContent(x-buffer.OffsetX, y-buffer.OffsetY, r, nil, style)
+			// Cursor is drawn over selections
+			if underCursor {
+				style = cursorStyle
+			}
+
+			// Replace whitespace with glyphs
+			char := r
+			if window.ShowWhitespace && i < len(buffer.Contents) {
+				if r == '\t' {
+					char = tabGlyph
+				} else if r == '\n' {
+					char = lineEndingGlyph
+				} else if r == ' ' && i >= trailingSpacesStart {
+					char = trailingSpaceGlyph
+				}
+
+				if char != r && !underCursor {
+					style = style.Foreground(CurrentStyle.WhitespaceFg)
+				}
+			}
+
+			window.screen.SetContent(x-buffer.OffsetX, y-buffer.OffsetY, char, nil, style)
+
+			// Show that the lines below are folded
+			if r == '\n' && buffer.IsFolded(line) {
+				window.screen.SetContent(x+1-buffer.OffsetX, y-buffer.OffsetY, '⋯', nil, bufferStyle.Foreground(CurrentStyle.FoldMarkerFg))
+			}
 		}
 
 		// Change position for next character
 		if r == '\n' {
+			trailingSpacesStart = getTrailingSpacesStart(buffer.Contents, i+1)
 			x = bufferX
 			y++
+			line++
+			col = 0
 		} else if r == '\t' {
 			x += int(Config.TabIndentation)
+			col += int(Config.TabIndentation)
 		} else {
 			x++
+			col++
+		}
+	}
+}
+
+// getTrailingSpacesStart returns the position where the whitespace at the end of the line starting at pos begins
+func getTrailingSpacesStart(contents string, pos int) int {
+	end := strings.IndexByte(contents[pos:], '\n')
+	if end == -1 {
+		end = len(contents)
+	} else {
+		end += pos
+	}
+
+	return pos + len(strings.TrimRight(contents[pos:end], " \t"))
+}
+
+// drawBufferDecorations highlights lines with cursors and draws rulers behind the text drawn by drawBuffer
+func drawBufferDecorations(window *Window) {
+	buffer := window.CurrentBuffer
+	screen := window.screen
+
+	x1, y1, x2, y2 := window.GetTextAreaDimensions()
+
+	// Get lines with cursors on them
+	cursorLines := make(map[int]bool)
+	if window.HighlightCurrentLine {
+		for _, cursor := range buffer.GetCursors() {
+			_, line := window.CursorPosToCursorPos2D(cursor.Pos)
+			cursorLines[line] = true
+		}
+	}
+
+	// Get screen columns of rulers
+	rulers := make(map[int]bool)
+	if window.ShowRulers {
+		for _, column := range Config.Rulers {
+			rulers[x1+column-buffer.OffsetX] = true
+		}
+	}
+
+	if len(cursorLines) == 0 && len(rulers) == 0 {
+		return
+	}
+
+	bufferStyle := CurrentStyle.GetStyle("buffer_area")
+
+	var rows []VisualRow
+	if window.UsesVisualRows() {
+		rows = window.GetVisualRows()
+	}
+	lineCount := buffer.GetLineCount()
+
+	for y := y1; y <= y2; y++ {
+		// Get line shown on this row, -1 below the end of the buffer
+		row := buffer.OffsetY + y - y1
+		line := row
+		if rows != nil {
+			line = -1
+			if row < len(rows) {
+				line = rows[row].Line
+			}
+		} else if row >= lineCount {
+			line = -1
+		}
+
+		for x := x1; x <= x2; x++ {
+			var color tcell.Color
+			if rulers[x] {
+				color = CurrentStyle.RulerBg
+			} else if cursorLines[line] {
+				color = CurrentStyle.CurrentLineBg
+			} else {
+				continue
+			}
+
+			// Only change cells without other highlights. Cells that were not drawn have the default screen style
+			r, combining, style, _ := screen.GetContent(x, y)
+			if style == tcell.StyleDefault {
+				style = bufferStyle
+			}
+			if _, bg, _ := style.Decompose(); bg == CurrentStyle.BufferAreaBg {
+				screen.SetContent(x, y, r, combining, style.Background(color))
+			}
+		}
+	}
+}
+
+// SetContents replaces the contents of the buffer. All changes to the contents must go through it, so caches are updated
+func (buffer *Buffer) SetContents(contents string) {
+	if len(buffer.folds) > 0 {
+		buffer.shiftFolds(buffer.Contents, contents)
+	}
+
+	buffer.Contents = contents
+	buffer.revision++
+}
+
+// GetLineCount returns the number of lines in the buffer. It is cached until the contents change
+func (buffer *Buffer) GetLineCount() int {
+	if buffer.lineCount == 0 || buffer.lineCountRevision != buffer.revision {
+		buffer.lineCount = strings.Count(buffer.Contents, "\n") + 1
+		buffer.lineCountRevision = buffer.revision
+	}
+
+	return buffer.lineCount
+}
+
+// GetEncoding returns "UTF-8" if the contents are valid UTF-8 and "binary" otherwise. It is cached until the contents change
+func (buffer *Buffer) GetEncoding() string {
+	if buffer.encoding == "" || buffer.encodingRevision != buffer.revision {
+		buffer.encoding = "binary"
+		if utf8.ValidString(buffer.Contents) {
+			buffer.encoding = "UTF-8"
+		}
+		buffer.encodingRevision = buffer.revision
+	}
+
+	return buffer.encoding
+}
+
+// GetLineEnding returns whether lines end with "LF", "CRLF" or "Mixed" line endings. It is cached until the contents change
+func (buffer *Buffer) GetLineEnding() string {
+	if buffer.lineEnding == "" || buffer.lineEndingRevision != buffer.revision {
+		crlf := strings.Count(buffer.Contents, "\r\n")
+		if crlf == 0 {
+			buffer.lineEnding = "LF"
+		} else if crlf == buffer.GetLineCount()-1 {
+			buffer.lineEnding = "CRLF"
+		} else {
+			buffer.lineEnding = "Mixed"
+		}
+		buffer.lineEndingRevision = buffer.revision
+	}
+
+	return buffer.lineEnding
+}
+
+// GetLineOffsets returns the position of the first character of every line.
+// It is cached until the contents change and must not be modified
+func (buffer *Buffer) GetLineOffsets() []int {
+	if buffer.lineOffsets != nil && buffer.lineOffsetsRevision == buffer.revision {
+		return buffer.lineOffsets
+	}
+
+	offsets := []int{0}
+	for i := 0; i < len(buffer.Contents); i++ {
+		if buffer.Contents[i] == '\n' {
+			offsets = append(offsets, i+1)
 		}
 	}
+
+	buffer.lineOffsets = offsets
+	buffer.lineOffsetsRevision = buffer.revision
+
+	return offsets
 }
 
 func (buffer *Buffer) Load() error {
@@ -113,7 +426,9 @@ func (buffer *Buffer) Load() error {
 		return err
 	}
 
-	buffer.Contents = string(content)
+	buffer.SetContents(string(content))
+	buffer.savedContents = buffer.Contents
+	buffer.modifiedLines = nil
 	return nil
 }
 
@@ -135,7 +450,7 @@ func (buffer *Buffer) Save() error {
 
 	// Append new line character at end of buffer contents if not present
 	if buffer.Contents == "" || buffer.Contents[len(buffer.Contents)-1] != '\n' {
-		buffer.Contents += "\n"
+		buffer.SetContents(buffer.Contents + "\n")
 	}
 
 	err := os.WriteFile(buffer.filename, []byte(buffer.Contents), 0644)
@@ -143,9 +458,16 @@ func (buffer *Buffer) Save() error {
 		return err
 	}
 
+	buffer.savedContents = buffer.Contents
+	buffer.modifiedLines = nil
 	return nil
 }
 
+// IsDirty checks if the buffer contents have changed since they were last loaded or saved
+func (buffer *Buffer) IsDirty() bool {
+	return buffer.canSave && !buffer.readOnly && buffer.Contents != buffer.savedContents
+}
+
 func (buffer *Buffer) GetSelectionEdges() (int, int) {
 	if buffer.Selection == nil {
 		return -1, -1
@@ -158,6 +480,40 @@ func (buffer *Buffer) GetSelectionEdges() (int, int) {
 	}
 }
 
+func (selection *Selection) GetEdges() (int, int) {
+	if selection.selectionStart < selection.selectionEnd {
+		return selection.selectionStart, selection.selectionEnd
+	} else {
+		return selection.selectionEnd, selection.selectionStart
+	}
+}
+
+func (buffer *Buffer) GetWordAt(pos int) (int, int) {
+	isWordChar := func(i int) bool {
+		currentRune := rune(buffer.Contents[i])
+		return unicode.IsLetter(currentRune) || unicode.IsDigit(currentRune) || currentRune == '_'
+	}
+
+	if pos < 0 || pos >= len(buffer.Contents) {
+		return pos, pos - 1
+	}
+
+	startOfWord := pos
+	endOfWord := pos
+
+	// Find end of word
+	for i := pos + 1; i < len(buffer.Contents) && isWordChar(i); i++ {
+		endOfWord++
+	}
+
+	// Find start of word
+	for i := pos - 1; i >= 0 && isWordChar(i); i-- {
+		startOfWord--
+	}
+
+	return startOfWord, endOfWord
+}
+
 func (buffer *Buffer) GetSelectedText() string {
 	if buffer.Selection == nil {
 		return ""
@@ -185,7 +541,15 @@ func (buffer *Buffer) GetSelectedText() string {
 }
 
 func (buffer *Buffer) CutText(window *Window) (string, int) {
-	if buffer.Selection == nil {
+	if buffer.BlockSelection != nil {
+		// Copy block selection
+		copiedText := buffer.GetBlockText()
+
+		// Remove selected text
+		window.DeleteBlockText(false)
+
+		return copiedText, 2
+	} else if buffer.Selection == nil {
 		// Copy line
 		copiedText := ""
 		startOfLine := window.CurrentBuffer.CursorPos
@@ -219,7 +583,7 @@ func (buffer *Buffer) CutText(window *Window) (string, int) {
 		}
 
 		// Remove line from buffer contents
-		buffer.Contents = buffer.Contents[:startOfLine] + buffer.Contents[endOfLine+1:]
+		buffer.SetContents(buffer.Contents[:startOfLine] + buffer.Contents[endOfLine+1:])
 
 		return copiedText, 0
 	} else {
@@ -232,7 +596,7 @@ func (buffer *Buffer) CutText(window *Window) (string, int) {
 			edge2 = len(buffer.Contents) - 1
 		}
 
-		buffer.Contents = buffer.Contents[:edge1] + buffer.Contents[edge2+1:]
+		buffer.SetContents(buffer.Contents[:edge1] + buffer.Contents[edge2+1:])
 		window.SetCursorPos(edge1)
 		buffer.Selection = nil
 
@@ -241,7 +605,10 @@ func (buffer *Buffer) CutText(window *Window) (string, int) {
 }
 
 func (buffer *Buffer) CopyText() (string, int) {
-	if buffer.Selection == nil {
+	if buffer.BlockSelection != nil {
+		// Copy block selection
+		return buffer.GetBlockText(), 2
+	} else if buffer.Selection == nil {
 		// Copy line
 		copiedText := ""
 
@@ -278,6 +645,12 @@ func (buffer *Buffer) CopyText() (string, int) {
 }
 
 func (buffer *Buffer) PasteText(window *Window, text string) {
+	// Paste text into every line of the block selection
+	if buffer.BlockSelection != nil {
+		window.InsertBlockText(text)
+		return
+	}
+
 	str := buffer.Contents
 
 	// Remove selected text
@@ -288,7 +661,7 @@ func (buffer *Buffer) PasteText(window *Window, text string) {
 		}
 
 		str = str[:edge1] + str[edge2+1:]
-		buffer.Contents = str
+		buffer.SetContents(str)
 		window.SetCursorPos(edge1)
 		buffer.Selection = nil
 	}
@@ -300,7 +673,7 @@ func (buffer *Buffer) PasteText(window *Window, text string) {
 	} else {
 		str = str[:index] + text + str[index:]
 	}
-	buffer.Contents = str
+	buffer.SetContents(str)
 	window.SetCursorPos(buffer.CursorPos + len(text))
 }
 
@@ -327,7 +700,7 @@ func (buffer *Buffer) FindAndReplaceSubstring(substring, replacement string, aft
 	}
 
 	// Replace substring with replacement string
-	buffer.Contents = buffer.Contents[:index] + replacement + buffer.Contents[index+len(substring):]
+	buffer.SetContents(buffer.Contents[:index] + replacement + buffer.Contents[index+len(substring):])
 
 	return index
 }
@@ -452,3 +825,29 @@ func CreateBuffer(bufferName string) (*Buffer, error) {
 
 	return &buffer, nil
 }
+
+// CloseBuffer removes a buffer and switches to a neighboring one if it was the current buffer.
+// The window is closed once no buffers are left
+func (window *Window) CloseBuffer(buffer *Buffer) {
+	bufferIndex := slices.Index(Buffers, buffer)
+	if bufferIndex == -1 {
+		return
+	}
+
+	Buffers = DeleteFromSlice(Buffers, bufferIndex)
+	if len(Buffers) == 0 {
+		window.Close()
+		return
+	}
+
+	if window.CurrentBuffer == buffer {
+		if bufferIndex >= len(Buffers) {
+			window.CurrentBuffer = Buffers[bufferIndex-1]
+		} else {
+			window.CurrentBuffer = Buffers[bufferIndex]
+		}
+		window.CursorMode = CursorModeBuffer
+	}
+
+	PrintMessage(window, "Buffer closed.")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/lucasb-eyer/go-colorful"
+	"maps"
+	"math"
+	"os"
+	"reflect"
+)
+
+// Number of colors the terminal can show, 0 if colors are disabled
+var terminalColors = 256
+
+// monochromeAttributes are added to slots when colors are disabled, so they stay distinguishable
+var monochromeAttributes = map[string]tcell.AttrMask{
+	"cursor":              tcell.AttrReverse,
+	"top_menu":            tcell.AttrReverse,
+	"status_line":         tcell.AttrReverse,
+	"tab_bar_active":      tcell.AttrReverse,
+	"line_index_current":  tcell.AttrBold,
+	"message_bar_warning": tcell.AttrBold,
+	"message_bar_error":   tcell.AttrBold | tcell.AttrReverse,
+}
+
+// getTerminalColors returns the number of colors the terminal supports, respecting NO_COLOR and COLORTERM
+func getTerminalColors(screen tcell.Screen) int {
+	if os.Getenv("NO_COLOR") != "" {
+		return 0
+	}
+
+	switch os.Getenv("COLORTERM") {
+	case "truecolor", "24bit", "24-bit":
+		return 1 << 24
+	}
+
+	return screen.Colors()
+}
+
+// getColorPalette returns the palette colors are converted to.
+// The first 16 colors are left out of the 256 color palette, as terminals often change them
+func getColorPalette(colors int) []tcell.Color {
+	first, last := 0, 8
+	if colors >= 256 {
+		first, last = 16, 256
+	} else if colors >= 16 {
+		last = 16
+	}
+
+	palette := make([]tcell.Color, 0, last-first)
+	for i := first; i < last; i++ {
+		palette = append(palette, tcell.PaletteColor(i))
+	}
+	return palette
+}
+
+// ConvertColor returns the color with the smallest perceptual distance to a color that the terminal can show
+func ConvertColor(color tcell.Color, colors int) tcell.Color {
+	if !color.Valid() {
+		return color
+	} else if colors <= 0 {
+		return tcell.ColorDefault
+	} else if colors >= 1<<24 {
+		return color
+	}
+
+	// Palette colors the terminal can show are kept, as the terminal may define them differently
+	if !color.IsRGB() && int(color-tcell.ColorValid) < colors {
+		return color
+	}
+
+	r, g, b := color.RGB()
+	target := colorful.Color{R: float64(r) / 255, G: float64(g) / 255, B: float64(b) / 255}
+
+	match, distance := tcell.ColorDefault, math.Inf(1)
+	for _, paletteColor := range getColorPalette(colors) {
+		r, g, b := paletteColor.RGB()
+		if d := target.DistanceCIEDE2000(colorful.Color{R: float64(r) / 255, G: float64(g) / 255, B: float64(b) / 255}); d < distance {
+			match, distance = paletteColor, d
+		}
+	}
+
+	return match
+}
+
+// convertStyleColors returns a copy of a style with all colors converted to ones the terminal can show
+func convertStyleColors(style TyperStyle, colors int) TyperStyle {
+	converted := style
+
+	v := reflect.ValueOf(&converted).Elem()
+	for _, i := range styleFieldIndices {
+		field := v.Field(i)
+		field.Set(reflect.ValueOf(ConvertColor(field.Interface().(tcell.Color), colors)))
+	}
+
+	converted.StatusSegments = make(map[string][2]tcell.Color)
+	for name, segmentColors := range style.StatusSegments {
+		converted.StatusSegments[name] = [2]tcell.Color{ConvertColor(segmentColors[0], colors), ConvertColor(segmentColors[1], colors)}
+	}
+
+	converted.Attributes = make(map[string]tcell.AttrMask)
+	maps.Copy(converted.Attributes, style.Attributes)
+	if colors <= 0 {
+		for slot, attributes := range monochromeAttributes {
+			converted.Attributes[slot] |= attributes
+		}
+	}
+
+	return converted
+}
+
+// highlightStyle changes the background of a style, or reverses it if colors are disabled
+func highlightStyle(style tcell.Style, color tcell.Color) tcell.Style {
+	if terminalColors <= 0 {
+		_, _, attributes := style.Decompose()
+		return style.Reverse(attributes&tcell.AttrReverse == 0)
+	}
+	return style.Background(color)
+}
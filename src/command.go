@@ -10,6 +10,7 @@ import (
 
 type Command struct {
 	cmd          string
+	description  string
 	run          func(window *Window, args ...string)
 	autocomplete func(window *Window, args ...string) []string
 }
@@ -19,8 +20,14 @@ var commands = make(map[string]*Command)
 func initCommands() {
 	// Setup commands
 	cutCmd := Command{
-		cmd: "cut",
+		cmd:         "cut",
+		description: "Cut the selection or current line to the clipboard",
 		run: func(window *Window, args ...string) {
+			if window.CurrentBuffer.readOnly {
+				PrintWarning(window, "Buffer is read-only!")
+				return
+			}
+
 			// Cut text from buffer
 			copiedText, copyingMethod := window.CurrentBuffer.CutText(window)
 
@@ -30,14 +37,17 @@ func initCommands() {
 			// Send appropriate message and remove text depending on copying method
 			if copyingMethod == 0 {
 				PrintMessage(window, "Copied line to clipboard.")
-			} else {
+			} else if copyingMethod == 1 {
 				PrintMessage(window, "Copied selection to clipboard.")
+			} else {
+				PrintMessage(window, "Copied block to clipboard.")
 			}
 		},
 	}
 
 	copyCmd := Command{
-		cmd: "copy",
+		cmd:         "copy",
+		description: "Copy the selection or current line to the clipboard",
 		run: func(window *Window, args ...string) {
 			// Copy text from buffer
 			copiedText, copyingMethod := window.CurrentBuffer.CopyText()
@@ -48,94 +58,143 @@ func initCommands() {
 			// Send appropriate message depending on copying method
 			if copyingMethod == 0 {
 				PrintMessage(window, "Copied line to clipboard.")
-			} else {
+			} else if copyingMethod == 1 {
 				PrintMessage(window, "Copied selection to clipboard.")
+			} else {
+				PrintMessage(window, "Copied block to clipboard.")
 			}
 		},
 	}
 
 	pasteCmd := Command{
-		cmd: "paste",
+		cmd:         "paste",
+		description: "Paste the clipboard contents",
 		run: func(window *Window, args ...string) {
+			if window.CurrentBuffer.readOnly {
+				PrintWarning(window, "Buffer is read-only!")
+				return
+			}
+
 			if window.Clipboard != "" {
-				window.CurrentBuffer.PasteText(window, window.Clipboard)
+				window.applyToCursors(func() {
+					window.CurrentBuffer.PasteText(window, window.Clipboard)
+				})
 				PrintMessage(window, "Pasted text to buffer.")
 			}
 		},
 	}
 
+	fileAutocomplete := func(window *Window, args ...string) []string {
+		if len(args) == 0 {
+			return CompleteFilePath(window, "")
+		}
+		return CompleteFilePath(window, args[len(args)-1])
+	}
+
 	saveCmd := Command{
-		cmd: "save",
+		cmd:         "save",
+		description: "Save the current buffer to a file",
 		run: func(window *Window, args ...string) {
 			if !window.CurrentBuffer.canSave {
-				PrintMessage(window, "Cannot save buffer!")
+				PrintError(window, "Cannot save buffer!")
 				return
 			}
 
-			inputChannel := RequestInput(window, "Save file [y\\N]:", "")
-			go func() {
-				input := <-inputChannel
-
-				if strings.ToLower(input) != "y" && strings.ToLower(input) != "yes" {
-					return
-				}
-
-				inputChannel = RequestInput(window, "Save buffer to:", window.CurrentBuffer.filename)
-
-				input = <-inputChannel
+			if len(args) >= 1 {
+				input := args[0]
 
 				if strings.TrimSpace(input) == "" {
-					PrintMessage(window, "No save location was given!")
+					PrintWarning(window, "No save location was given!")
 					return
 				}
 
 				window.CurrentBuffer.filename = strings.TrimSpace(input)
 				err := window.CurrentBuffer.Save()
 				if err != nil {
-
-					PrintMessage(window, fmt.Sprintf("Could not save file: %s", err))
+					PrintError(window, fmt.Sprintf("Could not save file: %s", err))
 					window.CurrentBuffer.filename = ""
 					return
 				}
 
 				PrintMessage(window, "File saved.")
-			}()
-		},
-		autocomplete: func(window *Window, args ...string) []string {
-			return nil
+
+				return
+			}
+
+			buffer := window.CurrentBuffer
+			RequestInput(window, "Save file [y\\N]:", "", "", func(input string, cancelled bool) {
+				if cancelled || (strings.ToLower(input) != "y" && strings.ToLower(input) != "yes") {
+					return
+				}
+
+				RequestInput(window, "Save buffer to:", buffer.filename, "save", func(input string, cancelled bool) {
+					if cancelled {
+						PrintMessage(window, "Save cancelled.")
+						return
+					}
+
+					if strings.TrimSpace(input) == "" {
+						PrintWarning(window, "No save location was given!")
+						return
+					}
+
+					buffer.filename = strings.TrimSpace(input)
+					err := buffer.Save()
+					if err != nil {
+						PrintError(window, fmt.Sprintf("Could not save file: %s", err))
+						buffer.filename = ""
+						return
+					}
+
+					PrintMessage(window, "File saved.")
+				})
+			})
 		},
+		autocomplete: fileAutocomplete,
 	}
 
 	openCmd := Command{
-		cmd: "open",
+		cmd:         "open",
+		description: "Open a file in a new buffer",
 		run: func(window *Window, args ...string) {
-			inputChannel := RequestInput(window, "File to open:", "")
-			go func() {
-				input := <-inputChannel
+			if len(args) >= 1 {
+				input := args[0]
 
 				if input == "" {
 					return
 				}
 
 				if openBuffer := GetOpenFileBuffer(input); openBuffer != nil {
-					PrintMessage(window, fmt.Sprintf("File already open! Switching to buffer: %s", openBuffer.Name))
+					PrintWarning(window, fmt.Sprintf("File already open! Switching to buffer: %s", openBuffer.Name))
 					window.CurrentBuffer = openBuffer
 				} else {
 					newBuffer, err := CreateFileBuffer(input, false)
 					if err != nil {
-						PrintMessage(window, fmt.Sprintf("Could not open file: %s", err.Error()))
+						PrintError(window, fmt.Sprintf("Could not open file: %s", err.Error()))
 						return
 					}
 
 					PrintMessage(window, fmt.Sprintf("Opening file at: %s", newBuffer.filename))
 					window.CurrentBuffer = newBuffer
 				}
-			}()
+
+				return
+			}
+
+			RequestInput(window, "File to open:", "", "open", func(input string, cancelled bool) {
+				if cancelled || input == "" {
+					return
+				}
+
+				RunCommand(window, "open", input)
+			})
 		},
+		autocomplete: fileAutocomplete,
 	}
 
 	reloadCmd := Command{
-		cmd: "reload",
+		cmd:         "reload",
+		description: "Reload the current buffer from disk",
 		run: func(window *Window, args ...string) {
 			err := window.CurrentBuffer.Load()
 			if err != nil {
@@ -148,7 +207,8 @@ func initCommands() {
 	}
 
 	findCmd := Command{
-		cmd: "find",
+		cmd:         "find",
+		description: "Find the next occurrence of a substring",
 		run: func(window *Window, args ...string) {
 			if len(args) >= 1 {
 				input := args[0]
@@ -156,40 +216,38 @@ func initCommands() {
 				if input == "" {
 					return
 				}
+				lastSearch = input
 
 				pos := window.CurrentBuffer.FindSubstring(input, window.CurrentBuffer.CursorPos)
 				if pos >= 0 {
 					window.SetCursorPos(pos)
 					PrintMessage(window, "Match found.")
 				} else {
-					PrintMessage(window, fmt.Sprintf("'%s' not found in buffer!", input))
+					PrintWarning(window, fmt.Sprintf("'%s' not found in buffer!", input))
 				}
 
 				return
 			}
 
-			inputChannel := RequestInput(window, "Substring to search for:", "")
-			go func() {
-				input := <-inputChannel
-
-				if input == "" {
+			RequestInput(window, "Substring to search for:", "", "find", func(input string, cancelled bool) {
+				if cancelled || input == "" {
 					return
 				}
 
-				pos := window.CurrentBuffer.FindSubstring(input, window.CurrentBuffer.CursorPos)
-				if pos >= 0 {
-					window.SetCursorPos(pos)
-					PrintMessage(window, "Match found.")
-				} else {
-					PrintMessage(window, fmt.Sprintf("'%s' not found in buffer!", input))
-				}
-			}()
+				RunCommand(window, "find", input)
+			})
 		},
 	}
 
 	replaceCmd := Command{
-		cmd: "replace",
+		cmd:         "replace",
+		description: "Replace the next occurrence of a substring",
 		run: func(window *Window, args ...string) {
+			if window.CurrentBuffer.readOnly {
+				PrintWarning(window, "Buffer is read-only!")
+				return
+			}
+
 			if len(args) >= 2 {
 				findStr := args[0]
 				replaceStr := args[1]
@@ -203,36 +261,37 @@ func initCommands() {
 					window.SetCursorPos(pos)
 					PrintMessage(window, "Match replaced successfully.")
 				} else {
-					PrintMessage(window, fmt.Sprintf("'%s' not found in buffer!", findStr))
+					PrintWarning(window, fmt.Sprintf("'%s' not found in buffer!", findStr))
 				}
 
 				return
 			}
 
-			go func() {
-				inputChannel := RequestInput(window, "Substring to search for:", "")
-				findStr := <-inputChannel
-				if findStr == "" {
+			RequestInput(window, "Substring to search for:", "", "find", func(findStr string, cancelled bool) {
+				if cancelled || findStr == "" {
 					return
 				}
 
-				inputChannel = RequestInput(window, "String to replace with:", "")
-				replaceStr := <-inputChannel
+				RequestInput(window, "String to replace with:", "", "replace", func(replaceStr string, cancelled bool) {
+					if cancelled {
+						return
+					}
 
-				pos := window.CurrentBuffer.FindAndReplaceSubstring(findStr, replaceStr, window.CurrentBuffer.CursorPos)
-				if pos >= 0 {
-					window.SetCursorPos(pos)
-					PrintMessage(window, "Match replaced successfully.")
-				} else {
-					PrintMessage(window, fmt.Sprintf("'%s' not found in buffer!", findStr))
-				}
-			}()
+					RunCommand(window, "replace", findStr, replaceStr)
+				})
+			})
 		},
 	}
 
 	replaceAllCmd := Command{
-		cmd: "replace-all",
+		cmd:         "replace-all",
+		description: "Replace all occurrences of a substring",
 		run: func(window *Window, args ...string) {
+			if window.CurrentBuffer.readOnly {
+				PrintWarning(window, "Buffer is read-only!")
+				return
+			}
+
 			if len(args) >= 2 {
 				findStr := args[0]
 				replaceStr := args[1]
@@ -246,35 +305,31 @@ func initCommands() {
 					window.SetCursorPos(window.CurrentBuffer.CursorPos)
 					PrintMessage(window, fmt.Sprintf("Replaced all %d matches successfully.", replacements))
 				} else {
-					PrintMessage(window, fmt.Sprintf("'%s' not found in buffer!", findStr))
+					PrintWarning(window, fmt.Sprintf("'%s' not found in buffer!", findStr))
 				}
 
 				return
 			}
 
-			go func() {
-				inputChannel := RequestInput(window, "Substring to search for:", "")
-				findStr := <-inputChannel
-				if findStr == "" {
+			RequestInput(window, "Substring to search for:", "", "find", func(findStr string, cancelled bool) {
+				if cancelled || findStr == "" {
 					return
 				}
 
-				inputChannel = RequestInput(window, "String to replace with:", "")
-				replaceStr := <-inputChannel
+				RequestInput(window, "String to replace with:", "", "replace", func(replaceStr string, cancelled bool) {
+					if cancelled {
+						return
+					}
 
-				replacements := window.CurrentBuffer.FindAndReplaceAll(findStr, replaceStr)
-				if replacements > 0 {
-					window.SetCursorPos(window.CurrentBuffer.CursorPos)
-					PrintMessage(window, fmt.Sprintf("Replaced all %d matches successfully.", replacements))
-				} else {
-					PrintMessage(window, fmt.Sprintf("'%s' not found in buffer!", findStr))
-				}
-			}()
+					RunCommand(window, "replace-all", findStr, replaceStr)
+				})
+			})
 		},
 	}
 
 	prevBufferCmd := Command{
-		cmd: "prev-buffer",
+		cmd:         "prev-buffer",
+		description: "Switch to the previous buffer",
 		run: func(window *Window, args ...string) {
 			if window.CursorMode != CursorModeBuffer {
 				return
@@ -293,7 +348,8 @@ func initCommands() {
 	}
 
 	nextBufferCmd := Command{
-		cmd: "next-buffer",
+		cmd:         "next-buffer",
+		description: "Switch to the next buffer",
 		run: func(window *Window, args ...string) {
 			if window.CursorMode != CursorModeBuffer {
 				return
@@ -312,7 +368,8 @@ func initCommands() {
 	}
 
 	newBufferCmd := Command{
-		cmd: "new-buffer",
+		cmd:         "new-buffer",
+		description: "Create a new empty buffer",
 		run: func(window *Window, args ...string) {
 			for i := 1; true; i++ {
 				buffer, err := CreateBuffer("New Buffer " + strconv.Itoa(i))
@@ -328,40 +385,135 @@ func initCommands() {
 	}
 
 	closeBufferCmd := Command{
-		cmd: "close-buffer",
+		cmd:         "close-buffer",
+		description: "Close the current buffer",
 		run: func(window *Window, args ...string) {
-			bufferIndex := slices.Index(Buffers, window.CurrentBuffer)
-			Buffers = DeleteFromSlice(Buffers, bufferIndex)
-			if len(Buffers) == 0 {
-				window.Close()
-				return
-			}
-			if bufferIndex >= len(Buffers) {
-				window.CurrentBuffer = Buffers[bufferIndex-1]
-			} else {
-				window.CurrentBuffer = Buffers[bufferIndex]
-			}
-			window.CursorMode = CursorModeBuffer
-			PrintMessage(window, "Buffer closed.")
+			window.CloseBuffer(window.CurrentBuffer)
+		},
+	}
+
+	moveBufferLeftCmd := Command{
+		cmd:         "move-buffer-left",
+		description: "Move the current buffer one position to the left in the tab bar",
+		run: func(window *Window, args ...string) {
+			window.MoveBuffer(-1)
+		},
+	}
+
+	moveBufferRightCmd := Command{
+		cmd:         "move-buffer-right",
+		description: "Move the current buffer one position to the right in the tab bar",
+		run: func(window *Window, args ...string) {
+			window.MoveBuffer(1)
 		},
 	}
 
 	toggleTopBar := Command{
-		cmd: "toggle-top-bar",
+		cmd:         "toggle-top-bar",
+		description: "Show or hide the top menu",
 		run: func(window *Window, args ...string) {
 			window.ShowTopMenu = !window.ShowTopMenu
+			window.SyncBufferOffset()
+		},
+	}
+
+	toggleStatusLine := Command{
+		cmd:         "toggle-status-line",
+		description: "Show or hide the status line",
+		run: func(window *Window, args ...string) {
+			window.ShowStatusLine = !window.ShowStatusLine
+			window.SyncBufferOffset()
+		},
+	}
+
+	toggleScrollbar := Command{
+		cmd:         "toggle-scrollbar",
+		description: "Show or hide the scrollbar",
+		run: func(window *Window, args ...string) {
+			window.ShowScrollbar = !window.ShowScrollbar
+			window.SyncBufferOffset()
+		},
+	}
+
+	toggleMinimap := Command{
+		cmd:         "toggle-minimap",
+		description: "Show or hide the minimap",
+		run: func(window *Window, args ...string) {
+			window.ShowMinimap = !window.ShowMinimap
+			window.SyncBufferOffset()
+		},
+	}
+
+	toggleCurrentLine := Command{
+		cmd:         "toggle-current-line",
+		description: "Highlight or stop highlighting lines with cursors",
+		run: func(window *Window, args ...string) {
+			window.HighlightCurrentLine = !window.HighlightCurrentLine
+		},
+	}
+
+	toggleWhitespace := Command{
+		cmd:         "toggle-whitespace",
+		description: "Show or hide tabs, trailing spaces and line endings",
+		run: func(window *Window, args ...string) {
+			window.ShowWhitespace = !window.ShowWhitespace
+		},
+	}
+
+	toggleRulers := Command{
+		cmd:         "toggle-rulers",
+		description: "Show or hide rulers",
+		run: func(window *Window, args ...string) {
+			window.ShowRulers = !window.ShowRulers
+		},
+	}
+
+	cycleLineNumbers := Command{
+		cmd:         "cycle-line-numbers",
+		description: "Switch between absolute, relative and hybrid line numbers",
+		run: func(window *Window, args ...string) {
+			index := slices.Index(lineNumberModes, window.LineNumbers)
+			window.LineNumbers = lineNumberModes[(index+1)%len(lineNumberModes)]
+			PrintMessage(window, fmt.Sprintf("Line numbers: %s", window.LineNumbers))
+		},
+	}
+
+	toggleTabBar := Command{
+		cmd:         "toggle-tab-bar",
+		description: "Show or hide the tab bar",
+		run: func(window *Window, args ...string) {
+			window.ShowTabBar = !window.ShowTabBar
 		},
 	}
 
 	toggleLineIndex := Command{
-		cmd: "toggle-line-index",
+		cmd:         "toggle-line-index",
+		description: "Show or hide the line index",
 		run: func(window *Window, args ...string) {
 			window.ShowLineIndex = !window.ShowLineIndex
 		},
 	}
 
+	toggleWrap := Command{
+		cmd:         "toggle-wrap",
+		description: "Enable or disable soft line wrapping",
+		run: func(window *Window, args ...string) {
+			window.SoftWrap = !window.SoftWrap
+			window.SyncBufferOffset()
+		},
+	}
+
+	styleAutocomplete := func(window *Window, args ...string) []string {
+		styles := make([]string, 0, len(AvailableStyles))
+		for name := range AvailableStyles {
+			styles = append(styles, name)
+		}
+		return styles
+	}
+
 	setStyleCmd := Command{
-		cmd: "set-style",
+		cmd:         "set-style",
+		description: "Change the editor style",
 		run: func(window *Window, args ...string) {
 			if len(args) >= 1 {
 				input := args[0]
@@ -371,123 +523,442 @@ func initCommands() {
 				}
 
 				if _, ok := AvailableStyles[input]; !ok {
-					PrintMessage(window, fmt.Sprintf("Could not set style to '%s'", input))
+					PrintError(window, fmt.Sprintf("Could not set style to '%s'", input))
 					return
 				}
 
 				if ok := SetCurrentStyle(window.screen, input); ok {
 					PrintMessage(window, fmt.Sprintf("Setting style to '%s'", input))
 				} else {
-					PrintMessage(window, fmt.Sprintf("Could not set style to '%s'", input))
+					PrintError(window, fmt.Sprintf("Could not set style to '%s'", input))
 				}
 
 				return
 			}
 
-			inputChannel := RequestInput(window, "Style to switch to:", "")
-			go func() {
-				input := <-inputChannel
-
-				if input == "" {
+			RequestInput(window, "Style to switch to:", "", "style", func(input string, cancelled bool) {
+				if cancelled || input == "" {
 					return
 				}
 
-				if _, ok := AvailableStyles[input]; !ok {
-					PrintMessage(window, fmt.Sprintf("Could not set style to '%s'", input))
-					return
+				RunCommand(window, "set-style", input)
+			})
+		},
+		autocomplete: styleAutocomplete,
+	}
+
+	openMenuCmd := Command{
+		cmd:         "open-menu",
+		description: "Open a top menu by name",
+		run: func(window *Window, args ...string) {
+			if len(args) >= 1 {
+				if !OpenTopMenu(window, args[0]) {
+					PrintWarning(window, fmt.Sprintf("Menu '%s' not found!", args[0]))
 				}
+				return
+			}
 
-				if ok := SetCurrentStyle(window.screen, input); ok {
-					PrintMessage(window, fmt.Sprintf("Setting style to '%s'", input))
-				} else {
-					PrintMessage(window, fmt.Sprintf("Could not set style to '%s'", input))
+			RequestInput(window, "Menu to open:", "", "", func(input string, cancelled bool) {
+				if cancelled || input == "" {
+					return
 				}
-			}()
+
+				RunCommand(window, "open-menu", input)
+			})
+		},
+		autocomplete: func(window *Window, args ...string) []string {
+			menus := make([]string, 0, len(TopMenuButtons))
+			for _, button := range TopMenuButtons {
+				menus = append(menus, button.Name)
+			}
+			return menus
 		},
 	}
 
 	menuFileCmd := Command{
-		cmd: "menu-file",
+		cmd:         "menu-file",
+		description: "Open the File menu",
 		run: func(window *Window, args ...string) {
-			for _, button := range TopMenuButtons {
-				if button.Name == "File" {
-					button.Action(window)
-					break
-				}
-			}
+			OpenTopMenu(window, "File")
 		},
 	}
 
 	menuEditCmd := Command{
-		cmd: "menu-edit",
+		cmd:         "menu-edit",
+		description: "Open the Edit menu",
 		run: func(window *Window, args ...string) {
-			for _, button := range TopMenuButtons {
-				if button.Name == "Edit" {
-					button.Action(window)
-					break
-				}
-			}
+			OpenTopMenu(window, "Edit")
+		},
+	}
+
+	menuViewCmd := Command{
+		cmd:         "menu-view",
+		description: "Open the View menu",
+		run: func(window *Window, args ...string) {
+			OpenTopMenu(window, "View")
 		},
 	}
 
 	menuBuffersCmd := Command{
-		cmd: "menu-buffers",
+		cmd:         "menu-buffers",
+		description: "Open the Buffers menu",
 		run: func(window *Window, args ...string) {
-			for _, button := range TopMenuButtons {
-				if button.Name == "Buffers" {
-					button.Action(window)
-					break
-				}
-			}
+			OpenTopMenu(window, "Buffers")
 		},
 	}
 
 	quitCmd := Command{
-		cmd: "quit",
+		cmd:         "quit",
+		description: "Quit the editor",
 		run: func(window *Window, args ...string) {
 			window.Close()
 			window.CursorMode = CursorModeBuffer
 		},
 	}
 
-	executeCmd := Command{
-		cmd: "execute",
+	addCursorNextMatchCmd := Command{
+		cmd:         "add-cursor-next-match",
+		description: "Add a cursor at the next occurrence of the selection",
 		run: func(window *Window, args ...string) {
-			inputChannel := RequestInput(window, "Run:", "")
+			if ok := window.AddCursorAtNextMatch(); !ok {
+				PrintWarning(window, "No more matches found!")
+			}
+		},
+	}
 
-			go func() {
-				input := strings.TrimSpace(<-inputChannel)
+	addCursorAboveCmd := Command{
+		cmd:         "add-cursor-above",
+		description: "Add a cursor on the line above",
+		run: func(window *Window, args ...string) {
+			window.AddCursorVertically(-1)
+		},
+	}
+
+	addCursorBelowCmd := Command{
+		cmd:         "add-cursor-below",
+		description: "Add a cursor on the line below",
+		run: func(window *Window, args ...string) {
+			window.AddCursorVertically(1)
+		},
+	}
+
+	addCursorsAtMatchesCmd := Command{
+		cmd:         "add-cursors-at-matches",
+		description: "Add a cursor at every occurrence of a substring",
+		run: func(window *Window, args ...string) {
+			if len(args) >= 1 || window.CurrentBuffer.Selection != nil {
+				input := window.CurrentBuffer.GetSelectedText()
+				if len(args) >= 1 {
+					input = args[0]
+				}
 
 				if input == "" {
 					return
 				}
 
-				var arguments []string
-
-				builder := &strings.Builder{}
-				quoted := false
-				for _, r := range input {
-					if r == '"' {
-						quoted = !quoted
-					} else if !quoted && r == ' ' {
-						arguments = append(arguments, builder.String())
-						builder.Reset()
-					} else {
-						builder.WriteRune(r)
-					}
+				if matches := window.AddCursorsAtMatches(input); matches > 0 {
+					PrintMessage(window, fmt.Sprintf("Added cursors at %d matches.", matches))
+				} else {
+					PrintWarning(window, fmt.Sprintf("'%s' not found in buffer!", input))
 				}
-				if builder.Len() > 0 {
-					arguments = append(arguments, builder.String())
+
+				return
+			}
+
+			RequestInput(window, "Substring to add cursors at:", "", "find", func(input string, cancelled bool) {
+				if cancelled || input == "" {
+					return
 				}
 
+				RunCommand(window, "add-cursors-at-matches", input)
+			})
+		},
+	}
+
+	selectAllCmd := Command{
+		cmd:         "select-all",
+		description: "Select the entire buffer",
+		run: func(window *Window, args ...string) {
+			window.SelectAll()
+		},
+	}
+
+	selectWordCmd := Command{
+		cmd:         "select-word",
+		description: "Select the word under the cursor",
+		run: func(window *Window, args ...string) {
+			window.SelectWord()
+		},
+	}
+
+	selectLineCmd := Command{
+		cmd:         "select-line",
+		description: "Select the current line",
+		run: func(window *Window, args ...string) {
+			window.SelectLine()
+		},
+	}
+
+	selectParagraphCmd := Command{
+		cmd:         "select-paragraph",
+		description: "Select the current paragraph",
+		run: func(window *Window, args ...string) {
+			window.SelectParagraph()
+		},
+	}
+
+	expandSelectionCmd := Command{
+		cmd:         "expand-selection",
+		description: "Grow the selection to the next enclosing region",
+		run: func(window *Window, args ...string) {
+			window.ExpandSelection()
+		},
+	}
+
+	gotoMatchingBracketCmd := Command{
+		cmd:         "goto-matching-bracket",
+		description: "Move the cursor to the matching bracket",
+		run: func(window *Window, args ...string) {
+			pos := window.CurrentBuffer.FindMatchingBracket(window.CurrentBuffer.CursorPos)
+			if pos == -1 {
+				PrintWarning(window, "No matching bracket found!")
+				return
+			}
+
+			window.CurrentBuffer.Selection = nil
+			window.SetCursorPos(pos)
+		},
+	}
+
+	foldCmd := Command{
+		cmd:         "fold",
+		description: "Fold the block at the cursor",
+		run: func(window *Window, args ...string) {
+			_, line := window.GetCursorPos2D()
+			if window.CurrentBuffer.Fold(line) == -1 {
+				PrintMessage(window, "Nothing to fold")
+				return
+			}
+
+			window.moveCursorOutOfFolds()
+		},
+	}
+
+	unfoldCmd := Command{
+		cmd:         "unfold",
+		description: "Unfold the block at the cursor",
+		run: func(window *Window, args ...string) {
+			_, line := window.GetCursorPos2D()
+			if !window.CurrentBuffer.Unfold(line, true) {
+				PrintMessage(window, "Nothing to unfold")
+				return
+			}
+
+			window.SyncBufferOffset()
+		},
+	}
+
+	toggleFoldCmd := Command{
+		cmd:         "toggle-fold",
+		description: "Fold or unfold the block at the cursor",
+		run: func(window *Window, args ...string) {
+			_, line := window.GetCursorPos2D()
+			if window.CurrentBuffer.Unfold(line, true) {
+				window.SyncBufferOffset()
+			} else if window.CurrentBuffer.Fold(line) != -1 {
+				window.moveCursorOutOfFolds()
+			} else {
+				PrintMessage(window, "Nothing to fold")
+			}
+		},
+	}
+
+	foldAllCmd := Command{
+		cmd:         "fold-all",
+		description: "Fold all blocks in the buffer",
+		run: func(window *Window, args ...string) {
+			window.CurrentBuffer.FoldAll()
+			window.moveCursorOutOfFolds()
+		},
+	}
+
+	unfoldAllCmd := Command{
+		cmd:         "unfold-all",
+		description: "Unfold all blocks in the buffer",
+		run: func(window *Window, args ...string) {
+			window.CurrentBuffer.UnfoldAll()
+			window.SyncBufferOffset()
+		},
+	}
+
+	toggleFileBrowserCmd := Command{
+		cmd:         "toggle-file-browser",
+		description: "Show or hide the file browser sidebar",
+		run: func(window *Window, args ...string) {
+			if window.ShowFileBrowser {
+				CloseFileBrowser(window)
+			} else {
+				OpenFileBrowser(window)
+			}
+		},
+	}
+
+	focusFileBrowserCmd := Command{
+		cmd:         "focus-file-browser",
+		description: "Switch focus between the file browser and the buffer",
+		run: func(window *Window, args ...string) {
+			if window.CursorMode == CursorModeFileBrowser {
 				window.CursorMode = CursorModeBuffer
+			} else {
+				OpenFileBrowser(window)
+			}
+		},
+	}
 
-				if len(arguments) == 1 {
-					RunCommand(window, arguments[0])
-				} else {
-					RunCommand(window, arguments[0], arguments[1:]...)
+	fileBrowserNewCmd := Command{
+		cmd:         "file-browser-new",
+		description: "Create a file or directory in the file browser",
+		run: func(window *Window, args ...string) {
+			OpenFileBrowser(window)
+			window.CreateFileInBrowser()
+		},
+	}
+
+	fileBrowserRenameCmd := Command{
+		cmd:         "file-browser-rename",
+		description: "Rename the selected file browser entry",
+		run: func(window *Window, args ...string) {
+			OpenFileBrowser(window)
+			window.RenameFileInBrowser()
+		},
+	}
+
+	fileBrowserDeleteCmd := Command{
+		cmd:         "file-browser-delete",
+		description: "Delete the selected file browser entry",
+		run: func(window *Window, args ...string) {
+			OpenFileBrowser(window)
+			window.DeleteFileInBrowser()
+		},
+	}
+
+	fileBrowserRefreshCmd := Command{
+		cmd:         "file-browser-refresh",
+		description: "Reload the file browser from disk",
+		run: func(window *Window, args ...string) {
+			fileBrowser.Refresh()
+		},
+	}
+
+	grepCmd := Command{
+		cmd:         "grep",
+		description: "Search all project files for a substring or /regex/",
+		run: func(window *Window, args ...string) {
+			if len(args) >= 1 {
+				if args[0] == "" {
+					return
+				}
+
+				StartGrep(window, args[0], "", false)
+				return
+			}
+
+			RequestInput(window, "Search project for (/regex/):", "", "find", func(input string, cancelled bool) {
+				if cancelled || input == "" {
+					return
+				}
+
+				RunCommand(window, "grep", input)
+			})
+		},
+	}
+
+	grepReplaceCmd := Command{
+		cmd:         "grep-replace",
+		description: "Replace a substring or /regex/ in all project files after a preview",
+		run: func(window *Window, args ...string) {
+			if len(args) >= 2 {
+				if args[0] == "" {
+					return
 				}
-			}()
+
+				StartGrep(window, args[0], args[1], true)
+				return
+			}
+
+			RequestInput(window, "Search project for (/regex/):", "", "find", func(findStr string, cancelled bool) {
+				if cancelled || findStr == "" {
+					return
+				}
+
+				RequestInput(window, "String to replace with:", "", "replace", func(replaceStr string, cancelled bool) {
+					if cancelled {
+						return
+					}
+
+					RunCommand(window, "grep-replace", findStr, replaceStr)
+				})
+			})
+		},
+	}
+
+	grepCancelCmd := Command{
+		cmd:         "grep-cancel",
+		description: "Stop the running project search",
+		run: func(window *Window, args ...string) {
+			if CancelGrep() {
+				PrintMessage(window, "Search cancelled.")
+			} else {
+				PrintWarning(window, "No search is running!")
+			}
+		},
+	}
+
+	showMessagesCmd := Command{
+		cmd:         "show-messages",
+		description: "Show all previous messages in a buffer",
+		run: func(window *Window, args ...string) {
+			ShowMessageLog(window)
+		},
+	}
+
+	dismissMessagesCmd := Command{
+		cmd:         "dismiss-messages",
+		description: "Hide error messages from the message bar",
+		run: func(window *Window, args ...string) {
+			DismissMessages()
+		},
+	}
+
+	helpCmd := Command{
+		cmd:         "help",
+		description: "Show commands, key bindings, config options and styles",
+		run: func(window *Window, args ...string) {
+			ShowHelp(window)
+		},
+	}
+
+	describeKeyCmd := Command{
+		cmd:         "describe-key",
+		description: "Show what the next pressed key is bound to",
+		run: func(window *Window, args ...string) {
+			StartDescribeKey(window)
+		},
+	}
+
+	findFileCmd := Command{
+		cmd:         "find-file",
+		description: "Open a file by fuzzy searching its path",
+		run: func(window *Window, args ...string) {
+			OpenFileFinder(window)
+		},
+	}
+
+	executeCmd := Command{
+		cmd:         "execute",
+		description: "Open the command palette",
+		run: func(window *Window, args ...string) {
+			OpenCommandPalette(window)
 		},
 	}
 
@@ -505,12 +976,54 @@ func initCommands() {
 	commands["next-buffer"] = &nextBufferCmd
 	commands["new-buffer"] = &newBufferCmd
 	commands["close-buffer"] = &closeBufferCmd
+	commands["move-buffer-left"] = &moveBufferLeftCmd
+	commands["move-buffer-right"] = &moveBufferRightCmd
 	commands["toggle-top-bar"] = &toggleTopBar
+	commands["toggle-tab-bar"] = &toggleTabBar
+	commands["toggle-status-line"] = &toggleStatusLine
+	commands["toggle-scrollbar"] = &toggleScrollbar
+	commands["toggle-minimap"] = &toggleMinimap
+	commands["toggle-current-line"] = &toggleCurrentLine
+	commands["toggle-whitespace"] = &toggleWhitespace
+	commands["toggle-rulers"] = &toggleRulers
+	commands["cycle-line-numbers"] = &cycleLineNumbers
 	commands["toggle-line-index"] = &toggleLineIndex
+	commands["toggle-wrap"] = &toggleWrap
 	commands["set-style"] = &setStyleCmd
+	commands["open-menu"] = &openMenuCmd
 	commands["menu-file"] = &menuFileCmd
 	commands["menu-edit"] = &menuEditCmd
+	commands["menu-view"] = &menuViewCmd
 	commands["menu-buffers"] = &menuBuffersCmd
+	commands["add-cursor-next-match"] = &addCursorNextMatchCmd
+	commands["add-cursor-above"] = &addCursorAboveCmd
+	commands["add-cursor-below"] = &addCursorBelowCmd
+	commands["add-cursors-at-matches"] = &addCursorsAtMatchesCmd
+	commands["select-all"] = &selectAllCmd
+	commands["select-word"] = &selectWordCmd
+	commands["select-line"] = &selectLineCmd
+	commands["select-paragraph"] = &selectParagraphCmd
+	commands["expand-selection"] = &expandSelectionCmd
+	commands["goto-matching-bracket"] = &gotoMatchingBracketCmd
+	commands["fold"] = &foldCmd
+	commands["unfold"] = &unfoldCmd
+	commands["toggle-fold"] = &toggleFoldCmd
+	commands["fold-all"] = &foldAllCmd
+	commands["unfold-all"] = &unfoldAllCmd
+	commands["toggle-file-browser"] = &toggleFileBrowserCmd
+	commands["focus-file-browser"] = &focusFileBrowserCmd
+	commands["file-browser-new"] = &fileBrowserNewCmd
+	commands["file-browser-rename"] = &fileBrowserRenameCmd
+	commands["file-browser-delete"] = &fileBrowserDeleteCmd
+	commands["file-browser-refresh"] = &fileBrowserRefreshCmd
+	commands["find-file"] = &findFileCmd
+	commands["grep"] = &grepCmd
+	commands["grep-replace"] = &grepReplaceCmd
+	commands["grep-cancel"] = &grepCancelCmd
+	commands["show-messages"] = &showMessagesCmd
+	commands["dismiss-messages"] = &dismissMessagesCmd
+	commands["help"] = &helpCmd
+	commands["describe-key"] = &describeKeyCmd
 	commands["quit"] = &quitCmd
 	commands["execute"] = &executeCmd
 }
@@ -520,7 +1033,7 @@ func RunCommand(window *Window, cmd string, args ...string) bool {
 		command.run(window, args...)
 		return true
 	} else {
-		PrintMessage(window, fmt.Sprintf("Could not find command '%s'!", cmd))
+		PrintError(window, fmt.Sprintf("Could not find command '%s'!", cmd))
 		return false
 	}
 }
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+type CommandPalette struct {
+	input     string
+	cursorPos int
+	Selected  int
+	Offset    int
+	Entries   []CommandPaletteEntry
+
+	historyIndex int
+	draft        string
+}
+
+type CommandPaletteEntry struct {
+	Label       string
+	Description string
+	Keybinding  string
+	value       string
+}
+
+const commandPaletteMaxEntries = 10
+
+// Input history kind of commands run from the command palette
+const commandPaletteHistoryKind = "run"
+
+var currentCommandPalette *CommandPalette
+
+func OpenCommandPalette(window *Window) {
+	ClearDropdowns()
+	CancelInput(window)
+	if currentFileFinder != nil {
+		CloseFileFinder(window)
+	}
+
+	currentCommandPalette = &CommandPalette{historyIndex: -1}
+	currentCommandPalette.updateEntries(window)
+
+	window.CursorMode = CursorModeCommandPalette
+}
+
+func CloseCommandPalette(window *Window) {
+	currentCommandPalette = nil
+	window.CursorMode = CursorModeBuffer
+}
+
+// ParseCommandLine splits a command line into arguments separated by spaces. Quoted arguments may contain spaces
+func ParseCommandLine(input string) []string {
+	var arguments []string
+
+	builder := &strings.Builder{}
+	quoted := false
+	for _, r := range input {
+		if r == '"' {
+			quoted = !quoted
+		} else if !quoted && r == ' ' {
+			arguments = append(arguments, builder.String())
+			builder.Reset()
+		} else {
+			builder.WriteRune(r)
+		}
+	}
+	if builder.Len() > 0 || strings.HasSuffix(input, " ") {
+		arguments = append(arguments, builder.String())
+	}
+
+	return arguments
+}
+
+// FormatCommandLine joins arguments into a command line, quoting arguments that contain spaces
+func FormatCommandLine(arguments []string) string {
+	quoted := make([]string, len(arguments))
+	for i, argument := range arguments {
+		if strings.ContainsRune(argument, ' ') || argument == "" {
+			argument = "\"" + argument + "\""
+		}
+		quoted[i] = argument
+	}
+	return strings.Join(quoted, " ")
+}
+
+func (palette *CommandPalette) updateEntries(window *Window) {
+	palette.Entries = make([]CommandPaletteEntry, 0)
+	palette.Selected = 0
+	palette.Offset = 0
+
+	type scoredEntry struct {
+		entry CommandPaletteEntry
+		score int
+	}
+	scored := make([]scoredEntry, 0)
+
+	arguments := ParseCommandLine(strings.TrimLeft(palette.input, " "))
+	if len(arguments) <= 1 {
+		// Fuzzy match command names
+		pattern := ""
+		if len(arguments) == 1 {
+			pattern = arguments[0]
+		}
+
+		for name, command := range commands {
+			score, ok := FuzzyMatch(pattern, name)
+			if !ok {
+				continue
+			}
+
+			scored = append(scored, scoredEntry{
+				entry: CommandPaletteEntry{
+					Label:       name,
+					Description: command.description,
+					Keybinding:  GetCommandKeybinding(name),
+					value:       name,
+				},
+				score: score,
+			})
+		}
+	} else if command, ok := commands[arguments[0]]; ok && command.autocomplete != nil {
+		// Complete last argument
+		pattern := arguments[len(arguments)-1]
+		for _, completion := range command.autocomplete(window, arguments[1:]...) {
+			score, ok := FuzzyMatch(pattern, completion)
+			if !ok {
+				continue
+			}
+
+			scored = append(scored, scoredEntry{
+				entry: CommandPaletteEntry{
+					Label: completion,
+					value: completion,
+				},
+				score: score,
+			})
+		}
+	}
+
+	slices.SortStableFunc(scored, func(a, b scoredEntry) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+		return strings.Compare(a.entry.Label, b.entry.Label)
+	})
+
+	for _, s := range scored {
+		palette.Entries = append(palette.Entries, s.entry)
+	}
+}
+
+// complete replaces the word being typed with the selected entry
+func (palette *CommandPalette) complete(window *Window) {
+	if len(palette.Entries) == 0 {
+		return
+	}
+
+	value := palette.Entries[palette.Selected].value
+
+	arguments := ParseCommandLine(strings.TrimLeft(palette.input, " "))
+	if len(arguments) <= 1 {
+		palette.input = FormatCommandLine([]string{value}) + " "
+	} else {
+		arguments[len(arguments)-1] = value
+		palette.input = FormatCommandLine(arguments)
+	}
+	palette.cursorPos = len(palette.input)
+
+	palette.updateEntries(window)
+}
+
+func (palette *CommandPalette) run(window *Window) {
+	input := strings.TrimSpace(palette.input)
+
+	// Use selected command if no arguments were typed
+	arguments := ParseCommandLine(input)
+	if len(arguments) <= 1 && len(palette.Entries) > 0 {
+		if _, ok := commands[input]; !ok {
+			arguments = []string{palette.Entries[palette.Selected].value}
+		}
+	}
+
+	CloseCommandPalette(window)
+
+	if len(arguments) == 0 {
+		return
+	}
+
+	AddToInputHistory(commandPaletteHistoryKind, FormatCommandLine(arguments))
+
+	if len(arguments) == 1 {
+		RunCommand(window, arguments[0])
+	} else {
+		RunCommand(window, arguments[0], arguments[1:]...)
+	}
+}
+
+// recallHistory replaces the input with an older (negative delta) or newer (positive delta) command that was run
+func (palette *CommandPalette) recallHistory(window *Window, delta int) {
+	history := inputHistory[commandPaletteHistoryKind]
+	if len(history) == 0 {
+		return
+	}
+
+	// Save typed input before browsing history
+	if palette.historyIndex == -1 {
+		if delta > 0 {
+			return
+		}
+		palette.draft = palette.input
+		palette.historyIndex = len(history)
+	}
+
+	palette.historyIndex = min(max(palette.historyIndex+delta, 0), len(history))
+
+	if palette.historyIndex == len(history) {
+		palette.historyIndex = -1
+		palette.input = palette.draft
+	} else {
+		palette.input = history[palette.historyIndex]
+	}
+	palette.cursorPos = len(palette.input)
+
+	palette.updateEntries(window)
+}
+
+func (palette *CommandPalette) moveSelection(delta int) {
+	if len(palette.Entries) == 0 {
+		return
+	}
+
+	palette.Selected = min(max(palette.Selected+delta, 0), len(palette.Entries)-1)
+
+	// Scroll entries
+	if palette.Selected < palette.Offset {
+		palette.Offset = palette.Selected
+	} else if palette.Selected >= palette.Offset+commandPaletteMaxEntries {
+		palette.Offset = palette.Selected - commandPaletteMaxEntries + 1
+	}
+}
+
+func handleCommandPaletteKey(window *Window, ev *tcell.EventKey) {
+	palette := currentCommandPalette
+
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		CloseCommandPalette(window)
+	case tcell.KeyEnter:
+		palette.run(window)
+	case tcell.KeyTab:
+		palette.complete(window)
+	case tcell.KeyUp:
+		// Recall commands run before when moving above the first entry
+		if palette.historyIndex != -1 || palette.Selected == 0 {
+			palette.recallHistory(window, -1)
+		} else {
+			palette.moveSelection(-1)
+		}
+	case tcell.KeyDown:
+		if palette.historyIndex != -1 {
+			palette.recallHistory(window, 1)
+		} else {
+			palette.moveSelection(1)
+		}
+	case tcell.KeyPgUp:
+		palette.moveSelection(-commandPaletteMaxEntries)
+	case tcell.KeyPgDn:
+		palette.moveSelection(commandPaletteMaxEntries)
+	case tcell.KeyLeft:
+		if palette.cursorPos > 0 {
+			_, size := utf8.DecodeLastRuneInString(palette.input[:palette.cursorPos])
+			palette.cursorPos -= size
+		}
+	case tcell.KeyRight:
+		if palette.cursorPos < len(palette.input) {
+			_, size := utf8.DecodeRuneInString(palette.input[palette.cursorPos:])
+			palette.cursorPos += size
+		}
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if palette.cursorPos > 0 {
+			_, size := utf8.DecodeLastRuneInString(palette.input[:palette.cursorPos])
+			palette.input = palette.input[:palette.cursorPos-size] + palette.input[palette.cursorPos:]
+			palette.cursorPos -= size
+			palette.historyIndex = -1
+			palette.updateEntries(window)
+		}
+	case tcell.KeyRune:
+		palette.input = palette.input[:palette.cursorPos] + string(ev.Rune()) + palette.input[palette.cursorPos:]
+		palette.cursorPos += utf8.RuneLen(ev.Rune())
+		palette.historyIndex = -1
+		palette.updateEntries(window)
+	}
+}
+
+func getCommandPaletteDimensions(window *Window) (int, int, int, int) {
+	sizeX, sizeY := window.screen.Size()
+
+	width := min(sizeX-4, 80)
+	x1 := (sizeX - width) / 2
+	y1 := 0
+	if window.ShowTopMenu {
+		y1++
+	}
+
+	rows := min(len(currentCommandPalette.Entries), commandPaletteMaxEntries)
+	y2 := min(y1+rows+3, sizeY-2)
+
+	return x1, y1, x1 + width - 1, y2
+}
+
+func drawCommandPalette(window *Window) {
+	palette := currentCommandPalette
+	if palette == nil {
+		return
+	}
+
+	screen := window.screen
+	paletteStyle := CurrentStyle.GetStyle("dropdown")
+
+	x1, y1, x2, y2 := getCommandPaletteDimensions(window)
+	drawBox(screen, x1, y1, x2, y2, paletteStyle)
+
+	// Draw input
+	drawText(screen, x1+1, y1+1, x2, y1+1, paletteStyle, "> "+palette.input)
+
+	// Draw separator
+	for x := x1 + 1; x < x2; x++ {
+		screen.SetContent(x, y1+2, tcell.RuneHLine, nil, paletteStyle)
+	}
+	screen.SetContent(x1, y1+2, tcell.RuneLTee, nil, paletteStyle)
+	screen.SetContent(x2, y1+2, tcell.RuneRTee, nil, paletteStyle)
+
+	// Draw entries
+	for i := palette.Offset; i < len(palette.Entries) && y1+3+i-palette.Offset < y2; i++ {
+		entry := palette.Entries[i]
+		y := y1 + 3 + i - palette.Offset
+
+		style := paletteStyle
+		if i == palette.Selected {
+			style = highlightStyle(style, CurrentStyle.DropdownSel)
+		}
+
+		for x := x1 + 1; x < x2; x++ {
+			screen.SetContent(x, y, ' ', nil, style)
+		}
+
+		drawText(screen, x1+2, y, x2, y, style, entry.Label)
+
+		if entry.Keybinding != "" && x2-len(entry.Keybinding)-1 > x1+2+len(entry.Label)+1 {
+			drawText(screen, x2-len(entry.Keybinding)-1, y, x2, y, style, entry.Keybinding)
+		}
+
+		descriptionX := x1 + 2 + 26
+		descriptionEnd := x2 - len(entry.Keybinding) - 2
+		if entry.Description != "" && descriptionX+4 < descriptionEnd && x1+2+len(entry.Label) < descriptionX {
+			description := entry.Description
+			if len(description) > descriptionEnd-descriptionX {
+				description = description[:descriptionEnd-descriptionX-3] + "..."
+			}
+			drawText(screen, descriptionX, y, descriptionEnd, y, style, description)
+		}
+	}
+}
+
+func getCommandPaletteCursorPos(window *Window) (int, int) {
+	x1, y1, _, _ := getCommandPaletteDimensions(window)
+	return x1 + 3 + utf8.RuneCountInString(currentCommandPalette.input[:currentCommandPalette.cursorPos]), y1 + 1
+}
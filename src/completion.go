@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+func initInputCompleters() {
+	RegisterInputCompleter("open", CompleteFilePath)
+	RegisterInputCompleter("save", CompleteFilePath)
+	RegisterInputCompleter("create", CompleteFilePath)
+	RegisterInputCompleter("rename", CompleteFilePath)
+	RegisterInputCompleter("style", CompleteStyleName)
+}
+
+// CompleteFilePath returns the paths of the files and directories starting with input. Directories end with a slash
+func CompleteFilePath(window *Window, input string) []string {
+	dir, prefix := filepath.Split(input)
+
+	// Replace tilde with home directory
+	readDir := dir
+	if strings.HasPrefix(readDir, "~/") {
+		homedir, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		readDir = filepath.Join(homedir, readDir[2:])
+	}
+	if readDir == "" {
+		readDir = "."
+	}
+
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil
+	}
+
+	completions := make([]string, 0)
+	for _, entry := range entries {
+		// Only show hidden files if explicitly requested
+		if !strings.HasPrefix(entry.Name(), prefix) || (strings.HasPrefix(entry.Name(), ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+
+		completion := dir + entry.Name()
+		if entry.IsDir() {
+			completion += "/"
+		}
+		completions = append(completions, completion)
+	}
+
+	return completions
+}
+
+func CompleteStyleName(window *Window, input string) []string {
+	completions := make([]string, 0)
+	for name := range AvailableStyles {
+		if strings.HasPrefix(name, input) {
+			completions = append(completions, name)
+		}
+	}
+	slices.Sort(completions)
+
+	return completions
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"path"
+	"slices"
 )
 
 type TyperConfig struct {
@@ -12,9 +13,46 @@ type TyperConfig struct {
 	FallbackStyle     string `yaml:"fallback_style,omitempty"`
 	ShowTopMenu       bool   `yaml:"show_top_menu,omitempty"`
 	ShowLineIndex     bool   `yaml:"show_line_index,omitempty"`
+	ShowTabBar        bool   `yaml:"show_tab_bar,omitempty"`
+	ShowStatusLine    bool   `yaml:"show_status_line,omitempty"`
 	ExtendLineIndex   bool   `yaml:"extend_line_index,omitempty"`
 	BufferInfoMessage string `yaml:"buffer_info_message,omitempty"`
 	TabIndentation    int    `yaml:"tab_indentation,omitempty"`
+	SoftWrap          bool   `yaml:"soft_wrap,omitempty"`
+	SoftWrapWords     bool   `yaml:"soft_wrap_words,omitempty"`
+	ShowFileBrowser   bool   `yaml:"show_file_browser,omitempty"`
+	FileBrowserWidth  int    `yaml:"file_browser_width,omitempty"`
+	ScrollSpeed       int    `yaml:"scroll_speed,omitempty"`
+	ShowScrollbar     bool   `yaml:"show_scrollbar,omitempty"`
+	ShowMinimap       bool   `yaml:"show_minimap,omitempty"`
+	MinimapWidth      int    `yaml:"minimap_width,omitempty"`
+
+	HighlightCurrentLine bool                   `yaml:"highlight_current_line,omitempty"`
+	ShowWhitespace       bool                   `yaml:"show_whitespace,omitempty"`
+	WhitespaceGlyphs     WhitespaceGlyphsConfig `yaml:"whitespace_glyphs,omitempty"`
+	ShowRulers           bool                   `yaml:"show_rulers,omitempty"`
+	Rulers               []int                  `yaml:"rulers,omitempty"`
+
+	LineNumbers     string `yaml:"line_numbers,omitempty"`
+	ShowSigns       bool   `yaml:"show_signs,omitempty"`
+	ShowFoldMarkers bool   `yaml:"show_fold_markers,omitempty"`
+
+	StatusLine StatusLineConfig `yaml:"status_line,omitempty"`
+
+	AutoClosePairs bool                `yaml:"auto_close_pairs,omitempty"`
+	AutoPairs      map[string][]string `yaml:"auto_pairs,omitempty"`
+}
+
+type StatusLineConfig struct {
+	Left   []string `yaml:"left"`
+	Center []string `yaml:"center"`
+	Right  []string `yaml:"right"`
+}
+
+type WhitespaceGlyphsConfig struct {
+	Tab           string `yaml:"tab"`
+	TrailingSpace string `yaml:"trailing_space"`
+	LineEnding    string `yaml:"line_ending"`
 }
 
 var Config TyperConfig
@@ -25,9 +63,47 @@ func readConfig() {
 		FallbackStyle:     "default-fallback",
 		ShowTopMenu:       true,
 		ShowLineIndex:     true,
+		ShowTabBar:        true,
+		ShowStatusLine:    true,
 		ExtendLineIndex:   false,
 		BufferInfoMessage: "File: %f Cursor: (%x, %y, %p) Chars: %c",
 		TabIndentation:    4,
+		SoftWrap:          false,
+		SoftWrapWords:     true,
+		ShowFileBrowser:   false,
+		FileBrowserWidth:  30,
+		ScrollSpeed:       3,
+		ShowScrollbar:     true,
+		ShowMinimap:       false,
+		MinimapWidth:      12,
+
+		HighlightCurrentLine: true,
+		ShowWhitespace:       false,
+		WhitespaceGlyphs: WhitespaceGlyphsConfig{
+			Tab:           "→",
+			TrailingSpace: "·",
+			LineEnding:    "¬",
+		},
+		ShowRulers: false,
+		Rulers:     []int{80, 120},
+
+		LineNumbers:     "absolute",
+		ShowSigns:       true,
+		ShowFoldMarkers: true,
+
+		StatusLine: StatusLineConfig{
+			Left:   []string{"path", "modified", "read_only"},
+			Center: []string{},
+			Right:  []string{"git_branch", "selection", "language", "encoding", "line_ending", "cursor", "percentage"},
+		},
+
+		AutoClosePairs: true,
+		AutoPairs: map[string][]string{
+			"default": {"()", "[]", "{}", "\"\"", "''"},
+			"go":      {"()", "[]", "{}", "\"\"", "''", "``"},
+			"python":  {"()", "[]", "{}", "\"\"", "''"},
+			"html":    {"<>", "\"\"", "''"},
+		},
 	}
 
 	homeDir, err := os.UserHomeDir()
@@ -60,4 +136,25 @@ func readConfig() {
 	if Config.TabIndentation < 1 {
 		Config.TabIndentation = 1
 	}
+	if Config.FileBrowserWidth < 10 {
+		Config.FileBrowserWidth = 10
+	}
+	if Config.ScrollSpeed < 1 {
+		Config.ScrollSpeed = 1
+	}
+	if Config.MinimapWidth < 4 {
+		Config.MinimapWidth = 4
+	}
+	if !slices.Contains(lineNumberModes, Config.LineNumbers) {
+		Config.LineNumbers = "absolute"
+	}
+	if Config.WhitespaceGlyphs.Tab == "" {
+		Config.WhitespaceGlyphs.Tab = " "
+	}
+	if Config.WhitespaceGlyphs.TrailingSpace == "" {
+		Config.WhitespaceGlyphs.TrailingSpace = " "
+	}
+	if Config.WhitespaceGlyphs.LineEnding == "" {
+		Config.WhitespaceGlyphs.LineEnding = " "
+	}
 }
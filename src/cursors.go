@@ -0,0 +1,229 @@
+package main
+
+import (
+	"slices"
+)
+
+type Cursor struct {
+	Pos       int
+	Selection *Selection
+}
+
+func (buffer *Buffer) GetCursors() []Cursor {
+	cursors := make([]Cursor, 0, len(buffer.ExtraCursors)+1)
+	cursors = append(cursors, Cursor{Pos: buffer.CursorPos, Selection: buffer.Selection})
+	for _, cursor := range buffer.ExtraCursors {
+		cursors = append(cursors, *cursor)
+	}
+
+	return cursors
+}
+
+func (buffer *Buffer) HasCursorAt(pos int) bool {
+	for _, cursor := range buffer.GetCursors() {
+		if cursor.Pos == pos {
+			return true
+		}
+	}
+	return false
+}
+
+func (buffer *Buffer) AddCursor(pos int, selection *Selection) bool {
+	if pos < 0 || pos > len(buffer.Contents) || buffer.HasCursorAt(pos) {
+		return false
+	}
+
+	buffer.ExtraCursors = append(buffer.ExtraCursors, &Cursor{
+		Pos:       pos,
+		Selection: selection,
+	})
+
+	return true
+}
+
+func (buffer *Buffer) RemoveCursorAt(pos int) bool {
+	for i, cursor := range buffer.ExtraCursors {
+		if cursor.Pos == pos {
+			buffer.ExtraCursors = DeleteFromSlice(buffer.ExtraCursors, i)
+			return true
+		}
+	}
+	return false
+}
+
+func (buffer *Buffer) CollapseCursors() {
+	buffer.ExtraCursors = nil
+}
+
+// MakeCursorPrimary moves the primary cursor into the extra cursors and puts a new primary cursor at pos
+func (window *Window) MakeCursorPrimary(pos int, selection *Selection) {
+	buffer := window.CurrentBuffer
+
+	buffer.RemoveCursorAt(pos)
+	if buffer.CursorPos != pos {
+		buffer.ExtraCursors = append(buffer.ExtraCursors, &Cursor{
+			Pos:       buffer.CursorPos,
+			Selection: buffer.Selection,
+		})
+	}
+
+	buffer.Selection = selection
+	window.SetCursorPos(pos)
+}
+
+// applyToCursors runs action once for every cursor of the current buffer, each time with that cursor acting as the primary one
+func (window *Window) applyToCursors(action func()) {
+	buffer := window.CurrentBuffer
+
+	if len(buffer.ExtraCursors) == 0 {
+		action()
+		return
+	}
+
+	cursors := buffer.GetCursors()
+
+	// Process cursors from the end of the buffer to the start, so edits do not move cursors that have not been processed yet
+	order := make([]int, len(cursors))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		return cursors[b].Pos - cursors[a].Pos
+	})
+
+	processed := make([]int, 0, len(cursors))
+	for _, i := range order {
+		buffer.CursorPos = cursors[i].Pos
+		buffer.Selection = cursors[i].Selection
+
+		lengthBefore := len(buffer.Contents)
+		action()
+		delta := len(buffer.Contents) - lengthBefore
+
+		cursors[i] = Cursor{Pos: buffer.CursorPos, Selection: buffer.Selection}
+
+		// Shift cursors placed after this one
+		if delta != 0 {
+			for _, j := range processed {
+				cursors[j].Pos += delta
+				if cursors[j].Selection != nil {
+					cursors[j].Selection.selectionStart += delta
+					cursors[j].Selection.selectionEnd += delta
+				}
+			}
+		}
+		processed = append(processed, i)
+	}
+
+	// Restore primary cursor and remove duplicate cursors
+	buffer.CursorPos = min(max(cursors[0].Pos, 0), len(buffer.Contents))
+	buffer.Selection = cursors[0].Selection
+	buffer.ExtraCursors = nil
+	for _, cursor := range cursors[1:] {
+		cursor.Pos = min(max(cursor.Pos, 0), len(buffer.Contents))
+		buffer.AddCursor(cursor.Pos, cursor.Selection)
+	}
+	window.SetCursorPos(buffer.CursorPos)
+}
+
+func (window *Window) AddCursorAtNextMatch() bool {
+	buffer := window.CurrentBuffer
+
+	// Select word under cursor first
+	if buffer.Selection == nil {
+		start, end := buffer.GetWordAt(buffer.CursorPos)
+		if start > end {
+			return false
+		}
+
+		buffer.Selection = &Selection{
+			selectionStart: start,
+			selectionEnd:   end,
+		}
+		window.SetCursorPos(end)
+		return true
+	}
+
+	text := buffer.GetSelectedText()
+	if text == "" {
+		return false
+	}
+
+	// Find the selection furthest into the buffer
+	last := -1
+	for _, cursor := range buffer.GetCursors() {
+		if cursor.Selection == nil {
+			continue
+		}
+
+		_, edge2 := cursor.Selection.GetEdges()
+		last = max(last, edge2)
+	}
+
+	pos := buffer.FindSubstring(text, last)
+	if pos == -1 {
+		// Wrap around to the start of the buffer
+		pos = buffer.FindSubstring(text, -1)
+	}
+	if pos == -1 || buffer.HasCursorAt(pos+len(text)-1) {
+		return false
+	}
+
+	window.MakeCursorPrimary(pos+len(text)-1, &Selection{
+		selectionStart: pos,
+		selectionEnd:   pos + len(text) - 1,
+	})
+
+	return true
+}
+
+func (window *Window) AddCursorsAtMatches(substring string) int {
+	buffer := window.CurrentBuffer
+
+	if substring == "" {
+		return 0
+	}
+
+	matches := 0
+	for pos := buffer.FindSubstring(substring, -1); pos != -1; pos = buffer.FindSubstring(substring, pos+len(substring)-1) {
+		selection := &Selection{
+			selectionStart: pos,
+			selectionEnd:   pos + len(substring) - 1,
+		}
+
+		if matches == 0 {
+			buffer.CollapseCursors()
+			buffer.Selection = selection
+			window.SetCursorPos(pos + len(substring) - 1)
+		} else {
+			buffer.AddCursor(pos+len(substring)-1, selection)
+		}
+		matches++
+	}
+
+	return matches
+}
+
+func (window *Window) AddCursorVertically(direction int) bool {
+	buffer := window.CurrentBuffer
+
+	// Find the top-most or bottom-most cursor
+	edge := buffer.CursorPos
+	for _, cursor := range buffer.ExtraCursors {
+		if (direction < 0 && cursor.Pos < edge) || (direction > 0 && cursor.Pos > edge) {
+			edge = cursor.Pos
+		}
+	}
+
+	x, y := window.CursorPosToCursorPos2D(edge)
+	if y+direction < 0 {
+		return false
+	}
+
+	pos := window.CursorPos2DToCursorPos(x, y+direction)
+	if _, newY := window.CursorPosToCursorPos2D(pos); newY != y+direction {
+		return false
+	}
+
+	return buffer.AddCursor(pos, nil)
+}
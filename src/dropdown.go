@@ -2,43 +2,80 @@ package main
 
 import (
 	"github.com/gdamore/tcell/v2"
+	"strings"
+	"unicode"
 )
 
+type DropdownItem struct {
+	Label     string
+	Command   string
+	Args      []string
+	Separator bool
+	Disabled  bool
+	Submenu   []DropdownItem
+
+	// Run instead of a command, used for generated items
+	Action func(window *Window)
+}
+
 type Dropdown struct {
 	Selected   int
-	Options    []string
+	Items      []DropdownItem
 	PosX, PosY int
 	Width      int
-	Action     func(int)
+	Parent     *Dropdown
 }
 
 var dropdowns = make([]*Dropdown, 0)
 var ActiveDropdown *Dropdown
 
-func CreateDropdownMenu(options []string, posX, posY, dropdownWidth int, action func(int)) *Dropdown {
-	if len(options) == 0 {
+// dropdownToggleStates returns the state of toggle commands, shown as a checkmark next to their items
+var dropdownToggleStates = map[string]func(window *Window) bool{
+	"toggle-top-bar":      func(window *Window) bool { return window.ShowTopMenu },
+	"toggle-tab-bar":      func(window *Window) bool { return window.ShowTabBar },
+	"toggle-status-line":  func(window *Window) bool { return window.ShowStatusLine },
+	"toggle-line-index":   func(window *Window) bool { return window.ShowLineIndex },
+	"toggle-wrap":         func(window *Window) bool { return window.SoftWrap },
+	"toggle-scrollbar":    func(window *Window) bool { return window.ShowScrollbar },
+	"toggle-minimap":      func(window *Window) bool { return window.ShowMinimap },
+	"toggle-file-browser": func(window *Window) bool { return window.ShowFileBrowser },
+	"toggle-current-line": func(window *Window) bool { return window.HighlightCurrentLine },
+	"toggle-whitespace":   func(window *Window) bool { return window.ShowWhitespace },
+	"toggle-rulers":       func(window *Window) bool { return window.ShowRulers },
+}
+
+func CreateDropdownMenu(items []DropdownItem, posX, posY, dropdownWidth int) *Dropdown {
+	if len(items) == 0 {
 		return nil
 	}
 
-	width := 0
-
+	width := dropdownWidth
 	if dropdownWidth <= 0 {
-		for _, option := range options {
-			if len(option) > width {
-				width = len(option)
-			}
+		labelWidth, hintWidth := 0, 0
+		for _, item := range items {
+			labelWidth = max(labelWidth, len([]rune(item.Label)))
+			hintWidth = max(hintWidth, len([]rune(item.getHint())))
+		}
+
+		// Leave space for checkmarks and hints
+		width = labelWidth + 2
+		if hintWidth > 0 {
+			width += hintWidth + 2
 		}
 	}
 
 	d := &Dropdown{
 		Selected: 0,
-		Options:  options,
+		Items:    items,
 		PosX:     posX,
 		PosY:     posY,
 		Width:    width,
-		Action:   action,
 	}
 
+	// Select first selectable item
+	d.Selected = -1
+	d.moveSelection(1)
+
 	dropdowns = append(dropdowns, d)
 
 	return d
@@ -49,19 +86,291 @@ func ClearDropdowns() {
 	ActiveDropdown = nil
 }
 
+// getHint returns the text shown right-aligned next to an item
+func (item *DropdownItem) getHint() string {
+	if item.Submenu != nil {
+		return "▸"
+	} else if item.Command != "" && len(item.Args) == 0 {
+		return GetCommandKeybinding(item.Command)
+	}
+	return ""
+}
+
+func (item *DropdownItem) isSelectable() bool {
+	if item.Separator || item.Disabled {
+		return false
+	}
+
+	// Items running missing commands are disabled
+	if item.Command != "" && item.Action == nil {
+		_, ok := commands[item.Command]
+		return ok
+	}
+
+	return item.Command != "" || item.Action != nil || item.Submenu != nil
+}
+
+func (item *DropdownItem) isChecked(window *Window) bool {
+	if state, ok := dropdownToggleStates[item.Command]; ok {
+		return state(window)
+	}
+	return false
+}
+
+// moveSelection moves the selection by delta selectable items, skipping separators and disabled items
+func (d *Dropdown) moveSelection(delta int) {
+	step := 1
+	if delta < 0 {
+		step = -1
+	}
+
+	for i := d.Selected + step; i >= 0 && i < len(d.Items) && delta != 0; i += step {
+		if d.Items[i].isSelectable() {
+			d.Selected = i
+			delta -= step
+		}
+	}
+}
+
+// closeSubmenus closes all dropdowns opened from this one
+func (d *Dropdown) closeSubmenus() {
+	for i, dropdown := range dropdowns {
+		if dropdown == d {
+			dropdowns = dropdowns[:i+1]
+			break
+		}
+	}
+}
+
+// openSubmenu opens the submenu of the selected item to the side of the dropdown
+func (d *Dropdown) openSubmenu(window *Window) *Dropdown {
+	d.closeSubmenus()
+
+	if d.Selected < 0 || d.Selected >= len(d.Items) || d.Items[d.Selected].Submenu == nil {
+		return nil
+	}
+
+	submenu := CreateDropdownMenu(d.Items[d.Selected].Submenu, d.PosX+d.Width+2, d.PosY+d.Selected, 0)
+	if submenu == nil {
+		return nil
+	}
+	submenu.Parent = d
+
+	// Open to the left if there is no space on the right
+	sizeX, _ := window.screen.Size()
+	if submenu.PosX+submenu.Width+1 >= sizeX {
+		submenu.PosX = max(d.PosX-submenu.Width-2, 0)
+	}
+
+	return submenu
+}
+
+// activateItem runs the command of an item or opens its submenu
+func (d *Dropdown) activateItem(window *Window, index int) {
+	if index < 0 || index >= len(d.Items) || !d.Items[index].isSelectable() {
+		return
+	}
+	d.Selected = index
+	item := d.Items[index]
+
+	if item.Submenu != nil {
+		if submenu := d.openSubmenu(window); submenu != nil {
+			ActiveDropdown = submenu
+		}
+		return
+	}
+
+	ClearDropdowns()
+	window.CursorMode = CursorModeBuffer
+
+	if item.Action != nil {
+		item.Action(window)
+	} else {
+		RunCommand(window, item.Command, item.Args...)
+	}
+}
+
+// closeDropdown closes a submenu and returns to its parent, or closes all dropdowns
+func closeDropdown(window *Window, d *Dropdown) {
+	if d.Parent != nil {
+		d.Parent.closeSubmenus()
+		ActiveDropdown = d.Parent
+		return
+	}
+
+	ClearDropdowns()
+	window.CursorMode = CursorModeBuffer
+}
+
+// jumpToItem selects the next item starting with the typed character and runs it if it is the only one.
+// If no label starts with the character, words inside labels are matched
+func (d *Dropdown) jumpToItem(window *Window, r rune) {
+	prefix := string(unicode.ToLower(r))
+
+	matches := make([]int, 0)
+	for i, item := range d.Items {
+		if item.isSelectable() && strings.HasPrefix(strings.ToLower(item.Label), prefix) {
+			matches = append(matches, i)
+		}
+	}
+
+	if len(matches) == 0 {
+		for i, item := range d.Items {
+			if !item.isSelectable() {
+				continue
+			}
+
+			for _, word := range strings.Fields(strings.ToLower(item.Label)) {
+				if strings.HasPrefix(word, prefix) {
+					matches = append(matches, i)
+					break
+				}
+			}
+		}
+	}
+
+	if len(matches) == 0 {
+		return
+	} else if len(matches) == 1 {
+		d.activateItem(window, matches[0])
+		return
+	}
+
+	// Cycle through matching items
+	for _, i := range matches {
+		if i > d.Selected {
+			d.Selected = i
+			return
+		}
+	}
+	d.Selected = matches[0]
+}
+
+func handleDropdownKey(window *Window, ev *tcell.EventKey) {
+	d := ActiveDropdown
+	if d == nil {
+		window.CursorMode = CursorModeBuffer
+		return
+	}
+
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		closeDropdown(window, d)
+	case tcell.KeyEnter:
+		d.activateItem(window, d.Selected)
+	case tcell.KeyUp:
+		d.moveSelection(-1)
+	case tcell.KeyDown:
+		d.moveSelection(1)
+	case tcell.KeyHome:
+		d.Selected = -1
+		d.moveSelection(1)
+	case tcell.KeyEnd:
+		d.Selected = len(d.Items)
+		d.moveSelection(-1)
+	case tcell.KeyRight:
+		if submenu := d.openSubmenu(window); submenu != nil {
+			ActiveDropdown = submenu
+		}
+	case tcell.KeyLeft:
+		if d.Parent != nil {
+			closeDropdown(window, d)
+		}
+	case tcell.KeyRune:
+		d.jumpToItem(window, ev.Rune())
+	}
+}
+
 func drawDropdowns(window *Window) {
-	dropdownStyle := tcell.StyleDefault.Background(CurrentStyle.DropdownBg).Foreground(CurrentStyle.DropdownFg)
+	screen := window.screen
+
+	dropdownStyle := CurrentStyle.GetStyle("dropdown")
 	for _, d := range dropdowns {
-		drawBox(window.screen, d.PosX, d.PosY, d.PosX+d.Width+1, d.PosY+len(d.Options)+1, dropdownStyle)
-		line := 1
-		for i, option := range d.Options {
-			if d.Selected == i {
-				drawText(window.screen, d.PosX+1, d.PosY+line, d.PosX+d.Width+1, d.PosY+line, dropdownStyle.Background(CurrentStyle.DropdownSel), option)
-			} else {
-				drawText(window.screen, d.PosX+1, d.PosY+line, d.PosX+d.Width+1, d.PosY+line, dropdownStyle, option)
+		drawBox(screen, d.PosX, d.PosY, d.PosX+d.Width+1, d.PosY+len(d.Items)+1, dropdownStyle)
+
+		for i, item := range d.Items {
+			y := d.PosY + 1 + i
+
+			if item.Separator {
+				screen.SetContent(d.PosX, y, tcell.RuneLTee, nil, dropdownStyle)
+				for x := d.PosX + 1; x <= d.PosX+d.Width; x++ {
+					screen.SetContent(x, y, tcell.RuneHLine, nil, dropdownStyle)
+				}
+				screen.SetContent(d.PosX+d.Width+1, y, tcell.RuneRTee, nil, dropdownStyle)
+				continue
+			}
+
+			style := dropdownStyle
+			if !item.isSelectable() {
+				style = style.Foreground(CurrentStyle.DropdownDisabled)
+			} else if d.Selected == i {
+				style = highlightStyle(style, CurrentStyle.DropdownSel)
 			}
 
-			line++
+			check := ' '
+			if item.isChecked(window) {
+				check = '✓'
+			}
+
+			// Fill row and draw checkmark, label and right-aligned hint
+			text := []rune(string(check) + " " + item.Label)
+			hint := []rune(item.getHint())
+			for x := 0; x < d.Width; x++ {
+				r := ' '
+				if x < len(text) {
+					r = text[x]
+				} else if hintX := x - (d.Width - len(hint)); hintX >= 0 && hintX < len(hint) {
+					r = hint[hintX]
+				}
+				screen.SetContent(d.PosX+1+x, y, r, nil, style)
+			}
+		}
+	}
+}
+
+// getDropdownAt returns the topmost dropdown containing the screen position and the item under it, or -1 on the border
+func getDropdownAt(x, y int) (*Dropdown, int) {
+	for i := len(dropdowns) - 1; i >= 0; i-- {
+		d := dropdowns[i]
+		if x < d.PosX || x > d.PosX+d.Width+1 || y < d.PosY || y > d.PosY+len(d.Items)+1 {
+			continue
+		}
+
+		if x > d.PosX && x <= d.PosX+d.Width && y > d.PosY && y <= d.PosY+len(d.Items) {
+			return d, y - d.PosY - 1
 		}
+		return d, -1
 	}
+
+	return nil, -1
+}
+
+// handleDropdownMouse selects items on hover and runs them on click. It returns false if the event was outside all dropdowns
+func handleDropdownMouse(window *Window, ev *tcell.EventMouse) bool {
+	mouseX, mouseY := ev.Position()
+
+	d, index := getDropdownAt(mouseX, mouseY)
+	if d == nil {
+		return false
+	}
+
+	if index != -1 && d.Items[index].isSelectable() {
+		// Open submenus on hover
+		if d.Selected != index || ActiveDropdown != d {
+			d.Selected = index
+			d.openSubmenu(window)
+		}
+		ActiveDropdown = d
+		window.CursorMode = CursorModeDropdown
+
+		if ev.Buttons() == tcell.Button1 && !mouseHeld {
+			d.activateItem(window, index)
+		}
+	}
+
+	if ev.Buttons()&(tcell.Button1|tcell.Button2|tcell.Button3) != 0 {
+		mouseHeld = true
+	}
+
+	return true
 }
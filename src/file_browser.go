@@ -0,0 +1,503 @@
+package main
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type FileBrowser struct {
+	Root     string
+	Entries  []FileBrowserEntry
+	Selected int
+	Offset   int
+
+	expanded  map[string]bool
+	gitIgnore *GitIgnore
+}
+
+type FileBrowserEntry struct {
+	Name  string
+	Path  string
+	IsDir bool
+	Depth int
+}
+
+var fileBrowser *FileBrowser
+
+func initFileBrowser() {
+	root, err := os.Getwd()
+	if err != nil {
+		root = "."
+	}
+
+	fileBrowser = &FileBrowser{
+		Root:      root,
+		Entries:   make([]FileBrowserEntry, 0),
+		expanded:  make(map[string]bool),
+		gitIgnore: CreateGitIgnore(root),
+	}
+}
+
+// Refresh re-reads the directory tree, keeping the selected entry if it still exists
+func (browser *FileBrowser) Refresh() {
+	selectedPath := ""
+	if browser.Selected < len(browser.Entries) {
+		selectedPath = browser.Entries[browser.Selected].Path
+	}
+
+	browser.gitIgnore = CreateGitIgnore(browser.Root)
+	browser.Entries = make([]FileBrowserEntry, 0)
+	browser.readDir(".", 0)
+
+	browser.Selected = 0
+	for i, entry := range browser.Entries {
+		if entry.Path == selectedPath {
+			browser.Selected = i
+			break
+		}
+	}
+	browser.moveSelection(0)
+}
+
+// readDir appends the entries of a directory relative to the root and the contents of its expanded subdirectories
+func (browser *FileBrowser) readDir(dir string, depth int) {
+	dirEntries, err := os.ReadDir(filepath.Join(browser.Root, dir))
+	if err != nil {
+		return
+	}
+
+	// List directories first
+	slices.SortStableFunc(dirEntries, func(a, b os.DirEntry) int {
+		if a.IsDir() != b.IsDir() {
+			if a.IsDir() {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(strings.ToLower(a.Name()), strings.ToLower(b.Name()))
+	})
+
+	for _, dirEntry := range dirEntries {
+		entryPath := filepath.Join(dir, dirEntry.Name())
+		if browser.gitIgnore.IsIgnored(entryPath, dirEntry.IsDir()) {
+			continue
+		}
+
+		browser.Entries = append(browser.Entries, FileBrowserEntry{
+			Name:  dirEntry.Name(),
+			Path:  entryPath,
+			IsDir: dirEntry.IsDir(),
+			Depth: depth,
+		})
+
+		if dirEntry.IsDir() && browser.expanded[entryPath] {
+			browser.readDir(entryPath, depth+1)
+		}
+	}
+}
+
+func (browser *FileBrowser) GetSelectedEntry() *FileBrowserEntry {
+	if browser.Selected < 0 || browser.Selected >= len(browser.Entries) {
+		return nil
+	}
+	return &browser.Entries[browser.Selected]
+}
+
+// getSelectedDir returns the directory new files are created in
+func (browser *FileBrowser) getSelectedDir() string {
+	entry := browser.GetSelectedEntry()
+	if entry == nil {
+		return "."
+	} else if entry.IsDir && browser.expanded[entry.Path] {
+		return entry.Path
+	}
+	return filepath.Dir(entry.Path)
+}
+
+func (browser *FileBrowser) moveSelection(delta int) {
+	browser.Selected = min(max(browser.Selected+delta, 0), max(len(browser.Entries)-1, 0))
+}
+
+func (browser *FileBrowser) SetExpanded(entryPath string, expanded bool) {
+	if expanded {
+		browser.expanded[entryPath] = true
+	} else {
+		delete(browser.expanded, entryPath)
+	}
+	browser.Refresh()
+}
+
+// activateEntry opens the selected file or toggles the selected directory
+func (browser *FileBrowser) activateEntry(window *Window) {
+	entry := browser.GetSelectedEntry()
+	if entry == nil {
+		return
+	}
+
+	if entry.IsDir {
+		browser.SetExpanded(entry.Path, !browser.expanded[entry.Path])
+		return
+	}
+
+	if openBuffer := GetOpenFileBuffer(entry.Path); openBuffer != nil {
+		window.CurrentBuffer = openBuffer
+	} else {
+		newBuffer, err := CreateFileBuffer(entry.Path, false)
+		if err != nil {
+			PrintError(window, fmt.Sprintf("Could not open file: %s", err.Error()))
+			return
+		}
+
+		PrintMessage(window, fmt.Sprintf("Opening file at: %s", newBuffer.filename))
+		window.CurrentBuffer = newBuffer
+	}
+
+	window.CursorMode = CursorModeBuffer
+}
+
+// OpenFileBrowser shows the file browser and focuses it
+func OpenFileBrowser(window *Window) {
+	if !window.ShowFileBrowser {
+		window.ShowFileBrowser = true
+		fileBrowser.Refresh()
+		window.SyncBufferOffset()
+	}
+
+	ClearDropdowns()
+	window.CursorMode = CursorModeFileBrowser
+}
+
+func CloseFileBrowser(window *Window) {
+	window.ShowFileBrowser = false
+	if window.CursorMode == CursorModeFileBrowser {
+		window.CursorMode = CursorModeBuffer
+	}
+	window.SyncBufferOffset()
+}
+
+func (window *Window) CreateFileInBrowser() {
+	dir := fileBrowser.getSelectedDir()
+
+	prefix := ""
+	if dir != "." {
+		prefix = dir + string(filepath.Separator)
+	}
+
+	RequestInput(window, "New file (end with / for a directory):", prefix, "create", func(input string, cancelled bool) {
+		focusFileBrowser(window)
+		if cancelled || strings.TrimSpace(input) == "" {
+			return
+		}
+
+		input = strings.TrimSpace(input)
+		newPath := filepath.Join(fileBrowser.Root, input)
+
+		var err error
+		if strings.HasSuffix(input, "/") {
+			err = os.MkdirAll(newPath, 0755)
+		} else {
+			err = os.MkdirAll(filepath.Dir(newPath), 0755)
+			if err == nil {
+				var file *os.File
+				file, err = os.OpenFile(newPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+				if err == nil {
+					err = file.Close()
+				}
+			}
+		}
+		if err != nil {
+			PrintError(window, fmt.Sprintf("Could not create file: %s", err))
+			return
+		}
+
+		// Expand parent directories to reveal the new file
+		for dir := filepath.Dir(filepath.Clean(input)); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+			fileBrowser.expanded[dir] = true
+		}
+		fileBrowser.Refresh()
+		for i, entry := range fileBrowser.Entries {
+			if entry.Path == filepath.Clean(input) {
+				fileBrowser.Selected = i
+			}
+		}
+
+		PrintMessage(window, fmt.Sprintf("Created %s.", input))
+	})
+}
+
+func (window *Window) RenameFileInBrowser() {
+	entry := fileBrowser.GetSelectedEntry()
+	if entry == nil {
+		return
+	}
+	oldPath := entry.Path
+
+	RequestInput(window, "Rename to:", oldPath, "rename", func(input string, cancelled bool) {
+		focusFileBrowser(window)
+		input = strings.TrimSpace(input)
+		if cancelled || input == "" || input == oldPath {
+			return
+		}
+
+		newPath := filepath.Join(fileBrowser.Root, input)
+		if _, err := os.Stat(newPath); err == nil {
+			PrintWarning(window, fmt.Sprintf("%s already exists!", input))
+			return
+		}
+
+		err := os.Rename(filepath.Join(fileBrowser.Root, oldPath), newPath)
+		if err != nil {
+			PrintError(window, fmt.Sprintf("Could not rename file: %s", err))
+			return
+		}
+
+		// Keep buffers of the renamed file or of files inside the renamed directory pointing to them
+		if newAbs, err := filepath.Abs(newPath); err == nil {
+			for buffer, rel := range getBuffersUnderPath(filepath.Join(fileBrowser.Root, oldPath)) {
+				buffer.filename = filepath.Join(newAbs, rel)
+				buffer.Name = filepath.Join(filepath.Clean(input), rel)
+			}
+		}
+
+		for dir := range fileBrowser.expanded {
+			if rel, ok := getRelativeSubpath(oldPath, dir); ok {
+				delete(fileBrowser.expanded, dir)
+				fileBrowser.expanded[filepath.Join(filepath.Clean(input), rel)] = true
+			}
+		}
+		fileBrowser.Refresh()
+
+		PrintMessage(window, fmt.Sprintf("Renamed %s to %s.", oldPath, input))
+	})
+}
+
+func (window *Window) DeleteFileInBrowser() {
+	entry := fileBrowser.GetSelectedEntry()
+	if entry == nil {
+		return
+	}
+	entryPath := entry.Path
+	isDir := entry.IsDir
+
+	RequestInput(window, fmt.Sprintf("Delete %s [y\\N]:", entryPath), "", "", func(input string, cancelled bool) {
+		focusFileBrowser(window)
+		if cancelled || (strings.ToLower(input) != "y" && strings.ToLower(input) != "yes") {
+			return
+		}
+
+		var err error
+		if isDir {
+			err = os.RemoveAll(filepath.Join(fileBrowser.Root, entryPath))
+		} else {
+			err = os.Remove(filepath.Join(fileBrowser.Root, entryPath))
+		}
+		if err != nil {
+			PrintError(window, fmt.Sprintf("Could not delete file: %s", err))
+			return
+		}
+
+		// Buffers of deleted files keep their contents, but have to be saved to write them again
+		for buffer := range getBuffersUnderPath(filepath.Join(fileBrowser.Root, entryPath)) {
+			buffer.savedContents = ""
+			buffer.modifiedLines = nil
+		}
+
+		for dir := range fileBrowser.expanded {
+			if _, ok := getRelativeSubpath(entryPath, dir); ok {
+				delete(fileBrowser.expanded, dir)
+			}
+		}
+		fileBrowser.Refresh()
+
+		PrintMessage(window, fmt.Sprintf("Deleted %s.", entryPath))
+	})
+}
+
+// getRelativeSubpath returns the path of target relative to base if target is base or inside it
+func getRelativeSubpath(base, target string) (string, bool) {
+	rel, err := filepath.Rel(base, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// getBuffersUnderPath returns the buffers of the file at path or of files inside it, along with their relative paths
+func getBuffersUnderPath(path string) map[*Buffer]string {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+
+	ret := make(map[*Buffer]string)
+	for _, buffer := range Buffers {
+		if buffer.filename == "" {
+			continue
+		}
+		if rel, ok := getRelativeSubpath(absPath, buffer.filename); ok {
+			ret[buffer] = rel
+		}
+	}
+
+	return ret
+}
+
+// focusFileBrowser returns focus to the file browser after a prompt if it is still shown
+func focusFileBrowser(window *Window) {
+	if window.ShowFileBrowser && window.CursorMode == CursorModeBuffer {
+		window.CursorMode = CursorModeFileBrowser
+	}
+}
+
+func handleFileBrowserKey(window *Window, ev *tcell.EventKey) {
+	browser := fileBrowser
+	_, y1, _, y2 := getFileBrowserDimensions(window)
+	pageSize := max(y2-y1, 1)
+
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		window.CursorMode = CursorModeBuffer
+	case tcell.KeyEnter:
+		browser.activateEntry(window)
+	case tcell.KeyUp:
+		browser.moveSelection(-1)
+	case tcell.KeyDown:
+		browser.moveSelection(1)
+	case tcell.KeyPgUp:
+		browser.moveSelection(-pageSize)
+	case tcell.KeyPgDn:
+		browser.moveSelection(pageSize)
+	case tcell.KeyHome:
+		browser.Selected = 0
+	case tcell.KeyEnd:
+		browser.moveSelection(len(browser.Entries))
+	case tcell.KeyRight:
+		if entry := browser.GetSelectedEntry(); entry != nil && entry.IsDir && !browser.expanded[entry.Path] {
+			browser.SetExpanded(entry.Path, true)
+		}
+	case tcell.KeyLeft:
+		entry := browser.GetSelectedEntry()
+		if entry == nil {
+			return
+		}
+
+		if entry.IsDir && browser.expanded[entry.Path] {
+			browser.SetExpanded(entry.Path, false)
+		} else if entry.Depth > 0 {
+			// Select parent directory
+			parent := filepath.Dir(entry.Path)
+			for i, e := range browser.Entries {
+				if e.Path == parent {
+					browser.Selected = i
+					break
+				}
+			}
+		}
+	}
+}
+
+func handleFileBrowserClick(window *Window, mouseX, mouseY int) {
+	_, y1, _, y2 := getFileBrowserDimensions(window)
+	if mouseY <= y1 || mouseY > y2 {
+		window.CursorMode = CursorModeFileBrowser
+		return
+	}
+
+	index := fileBrowser.Offset + mouseY - y1 - 1
+	if index >= len(fileBrowser.Entries) {
+		window.CursorMode = CursorModeFileBrowser
+		return
+	}
+
+	fileBrowser.Selected = index
+	window.CursorMode = CursorModeFileBrowser
+	fileBrowser.activateEntry(window)
+}
+
+// getFileBrowserWidth returns the width of the file browser including its border, or 0 if it is hidden
+func getFileBrowserWidth(window *Window) int {
+	if !window.ShowFileBrowser {
+		return 0
+	}
+
+	sizeX, _ := window.screen.Size()
+	return max(min(Config.FileBrowserWidth, sizeX/2), 0)
+}
+
+func getFileBrowserDimensions(window *Window) (int, int, int, int) {
+	_, y1, _, y2 := window.GetTextAreaDimensions()
+
+	return 0, y1, getFileBrowserWidth(window) - 1, y2
+}
+
+func drawFileBrowser(window *Window) {
+	browser := fileBrowser
+	screen := window.screen
+
+	browserStyle := CurrentStyle.GetStyle("file_browser")
+
+	x1, y1, x2, y2 := getFileBrowserDimensions(window)
+	if x2 <= x1 {
+		return
+	}
+
+	// Keep selected entry visible
+	rows := y2 - y1
+	if browser.Selected < browser.Offset {
+		browser.Offset = browser.Selected
+	} else if browser.Selected >= browser.Offset+rows {
+		browser.Offset = browser.Selected - rows + 1
+	}
+	browser.Offset = max(min(browser.Offset, len(browser.Entries)-rows), 0)
+
+	// Draw background and border
+	for y := y1; y <= y2; y++ {
+		for x := x1; x < x2; x++ {
+			screen.SetContent(x, y, ' ', nil, browserStyle)
+		}
+		screen.SetContent(x2, y, tcell.RuneVLine, nil, browserStyle)
+	}
+
+	// Draw root directory name
+	drawText(screen, x1+1, y1, x2, y1, browserStyle.Bold(true), filepath.Base(browser.Root)+"/")
+
+	// Draw entries
+	for i := browser.Offset; i < len(browser.Entries) && y1+1+i-browser.Offset <= y2; i++ {
+		entry := browser.Entries[i]
+		y := y1 + 1 + i - browser.Offset
+
+		style := browserStyle
+		if entry.IsDir {
+			style = style.Foreground(CurrentStyle.FileBrowserDir)
+		}
+		if i == browser.Selected && window.CursorMode == CursorModeFileBrowser {
+			style = highlightStyle(style, CurrentStyle.FileBrowserSel)
+			for x := x1; x < x2; x++ {
+				screen.SetContent(x, y, ' ', nil, style)
+			}
+		}
+
+		text := strings.Repeat("  ", entry.Depth)
+		if entry.IsDir && browser.expanded[entry.Path] {
+			text += "▾ " + entry.Name + "/"
+		} else if entry.IsDir {
+			text += "▸ " + entry.Name + "/"
+		} else {
+			text += "  " + entry.Name
+		}
+
+		// Truncate long names
+		runes := []rune(text)
+		if len(runes) > x2-x1-1 {
+			runes = append(runes[:max(x2-x1-2, 0)], '…')
+		}
+
+		for j, r := range runes {
+			screen.SetContent(x1+1+j, y, r, nil, style)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"gopkg.in/yaml.v3"
+)
+
+func TestFileBrowserRuneKeybindings(t *testing.T) {
+	readConfig()
+	initCommands()
+
+	data, err := os.ReadFile("../config/keybindings.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	Keybindings = TyperKeybindings{}
+	if err := yaml.Unmarshal(data, &Keybindings); err != nil {
+		t.Fatal(err)
+	}
+
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(80, 24)
+
+	buffer := &Buffer{Name: "test", canSave: true}
+	window := &Window{screen: screen, CurrentBuffer: buffer, ShowLineIndex: true, LineNumbers: "absolute"}
+
+	fileBrowser = &FileBrowser{
+		Root:     t.TempDir(),
+		Entries:  make([]FileBrowserEntry, 0),
+		expanded: make(map[string]bool),
+	}
+	OpenFileBrowser(window)
+	defer func() {
+		currentInputRequest = nil
+	}()
+
+	window.handleKeyInput(tcell.NewEventKey(tcell.KeyRune, 'n', tcell.ModNone))
+
+	if window.CursorMode != CursorModeInputBar || currentInputRequest == nil {
+		t.Fatalf("expected create prompt to open, cursor mode is %d", window.CursorMode)
+	}
+	if currentInputRequest.Text != "New file (end with / for a directory):" {
+		t.Fatalf("unexpected prompt (%s)", currentInputRequest.Text)
+	}
+
+	// Letters typed into the prompt must not run file browser commands
+	window.handleKeyInput(tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone))
+	if currentInputRequest == nil || currentInputRequest.input != "d" {
+		t.Fatal("expected typed letter to be inserted into the prompt")
+	}
+}
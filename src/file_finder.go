@@ -0,0 +1,434 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type FileFinder struct {
+	input     string
+	cursorPos int
+	Selected  int
+	Offset    int
+	Entries   []string
+
+	// Scores of the entries
+	scores []int
+
+	files   []string
+	walking bool
+	done    chan struct{}
+}
+
+const fileFinderMaxEntries = 15
+const fileFinderMaxResults = 500
+
+var currentFileFinder *FileFinder
+
+func OpenFileFinder(window *Window) {
+	ClearDropdowns()
+	CancelInput(window)
+	if currentCommandPalette != nil {
+		CloseCommandPalette(window)
+	}
+	if currentFileFinder != nil {
+		CloseFileFinder(window)
+	}
+
+	root := fileBrowser.Root
+
+	finder := &FileFinder{
+		Entries: make([]string, 0),
+		files:   make([]string, 0),
+		walking: true,
+		done:    make(chan struct{}),
+	}
+	currentFileFinder = finder
+
+	window.CursorMode = CursorModeFileFinder
+
+	// Walk directory tree in the background and add files in batches
+	go func() {
+		batch := make([]string, 0)
+		lastBatch := time.Now()
+
+		sendBatch := func(walking bool) {
+			files := batch
+			batch = make([]string, 0)
+			lastBatch = time.Now()
+
+			window.RunOnMainLoop(func() {
+				if currentFileFinder != finder {
+					return
+				}
+
+				finder.walking = walking
+				finder.addFiles(files)
+			})
+		}
+
+		walkFiles(root, finder.done, func(relPath string) {
+			batch = append(batch, relPath)
+			if len(batch) >= 256 || time.Since(lastBatch) > 50*time.Millisecond {
+				sendBatch(true)
+			}
+		})
+
+		sendBatch(false)
+	}()
+}
+
+func CloseFileFinder(window *Window) {
+	if currentFileFinder != nil {
+		close(currentFileFinder.done)
+	}
+
+	currentFileFinder = nil
+	window.CursorMode = CursorModeBuffer
+}
+
+// walkFiles calls found with the path relative to root of every file that is not ignored or binary.
+// It stops early once done is closed
+func walkFiles(root string, done chan struct{}, found func(relPath string)) {
+	gitIgnore := CreateGitIgnore(root)
+
+	_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		select {
+		case <-done:
+			return filepath.SkipAll
+		default:
+		}
+
+		if err != nil {
+			if entry != nil && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil || relPath == "." {
+			return nil
+		}
+
+		if gitIgnore.IsIgnored(relPath, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !entry.Type().IsRegular() || isBinaryFile(path) {
+			return nil
+		}
+
+		found(relPath)
+		return nil
+	})
+}
+
+// isBinaryFile checks if the beginning of a file contains null bytes
+func isBinaryFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return true
+	}
+	defer file.Close()
+
+	data := make([]byte, 512)
+	n, err := file.Read(data)
+	if err != nil && err != io.EOF {
+		return true
+	}
+
+	return bytes.IndexByte(data[:n], 0) != -1
+}
+
+// ParseFileLocation splits a "path:line:column" string into its parts. Line and column are 0 if not given
+func ParseFileLocation(input string) (string, int, int) {
+	path := input
+	numbers := make([]int, 0, 2)
+
+	for len(numbers) < 2 {
+		index := strings.LastIndex(path, ":")
+		if index == -1 {
+			break
+		}
+
+		n, err := strconv.Atoi(path[index+1:])
+		if err != nil || n < 1 {
+			break
+		}
+
+		numbers = append(numbers, n)
+		path = path[:index]
+	}
+
+	if len(numbers) == 2 {
+		return path, numbers[1], numbers[0]
+	} else if len(numbers) == 1 {
+		return path, numbers[0], 0
+	}
+	return path, 0, 0
+}
+
+type fileFinderMatch struct {
+	path  string
+	score int
+}
+
+// scoreFiles returns the files matching the input, best matches first
+func (finder *FileFinder) scoreFiles(files []string) []fileFinderMatch {
+	pattern, _, _ := ParseFileLocation(finder.input)
+	pattern = strings.ReplaceAll(pattern, " ", "")
+
+	matches := make([]fileFinderMatch, 0)
+	for _, file := range files {
+		score, ok := FuzzyMatch(pattern, file)
+		if !ok {
+			continue
+		}
+
+		// Prefer matches in the file name
+		if _, ok := FuzzyMatch(pattern, filepath.Base(file)); ok && pattern != "" {
+			score += 10
+		}
+
+		matches = append(matches, fileFinderMatch{path: file, score: score})
+	}
+
+	sortFileFinderMatches(matches)
+	return matches
+}
+
+func sortFileFinderMatches(matches []fileFinderMatch) {
+	slices.SortStableFunc(matches, func(a, b fileFinderMatch) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+		return strings.Compare(a.path, b.path)
+	})
+}
+
+// updateEntries scores all files again after the input changed
+func (finder *FileFinder) updateEntries() {
+	finder.setMatches(finder.scoreFiles(finder.files))
+}
+
+// addFiles adds files found while walking the directory tree, only scoring the new files.
+// The selected file stays selected if it is still among the entries
+func (finder *FileFinder) addFiles(files []string) {
+	finder.files = append(finder.files, files...)
+
+	selectedPath := ""
+	if finder.Selected < len(finder.Entries) {
+		selectedPath = finder.Entries[finder.Selected]
+	}
+
+	matches := make([]fileFinderMatch, 0, len(finder.Entries)+len(files))
+	for i, path := range finder.Entries {
+		matches = append(matches, fileFinderMatch{path: path, score: finder.scores[i]})
+	}
+	matches = append(matches, finder.scoreFiles(files)...)
+	sortFileFinderMatches(matches)
+
+	finder.setMatches(matches)
+	if i := slices.Index(finder.Entries, selectedPath); i != -1 {
+		finder.Selected = i
+		finder.moveSelection(0)
+	}
+}
+
+// setMatches shows the best matches as entries
+func (finder *FileFinder) setMatches(matches []fileFinderMatch) {
+	count := min(len(matches), fileFinderMaxResults)
+	finder.Entries = make([]string, 0, count)
+	finder.scores = make([]int, 0, count)
+	for _, match := range matches[:count] {
+		finder.Entries = append(finder.Entries, match.path)
+		finder.scores = append(finder.scores, match.score)
+	}
+
+	finder.Selected = min(finder.Selected, max(len(finder.Entries)-1, 0))
+	finder.moveSelection(0)
+}
+
+func (finder *FileFinder) moveSelection(delta int) {
+	if len(finder.Entries) == 0 {
+		finder.Selected = 0
+		finder.Offset = 0
+		return
+	}
+
+	finder.Selected = min(max(finder.Selected+delta, 0), len(finder.Entries)-1)
+
+	// Scroll entries
+	if finder.Selected < finder.Offset {
+		finder.Offset = finder.Selected
+	} else if finder.Selected >= finder.Offset+fileFinderMaxEntries {
+		finder.Offset = finder.Selected - fileFinderMaxEntries + 1
+	}
+}
+
+func (finder *FileFinder) open(window *Window) {
+	path, line, column := ParseFileLocation(finder.input)
+	if len(finder.Entries) > 0 {
+		path = finder.Entries[finder.Selected]
+	}
+
+	CloseFileFinder(window)
+
+	if strings.TrimSpace(path) == "" {
+		return
+	}
+
+	OpenFileLocation(window, path, line, column)
+}
+
+// OpenFileLocation opens a file and moves the cursor to the given line and column if they are greater than 0
+func OpenFileLocation(window *Window, path string, line int, column int) bool {
+	if !RunCommand(window, "open", path) || GetOpenFileBuffer(path) != window.CurrentBuffer {
+		return false
+	}
+
+	if line > 0 {
+		window.CurrentBuffer.Selection = nil
+		window.CurrentBuffer.CollapseCursors()
+		window.CurrentBuffer.BlockSelection = nil
+		window.SetCursorPos2D(max(column-1, 0), line-1)
+	}
+
+	return true
+}
+
+func handleFileFinderKey(window *Window, ev *tcell.EventKey) {
+	finder := currentFileFinder
+
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		CloseFileFinder(window)
+	case tcell.KeyEnter:
+		finder.open(window)
+	case tcell.KeyUp:
+		finder.moveSelection(-1)
+	case tcell.KeyDown:
+		finder.moveSelection(1)
+	case tcell.KeyPgUp:
+		finder.moveSelection(-fileFinderMaxEntries)
+	case tcell.KeyPgDn:
+		finder.moveSelection(fileFinderMaxEntries)
+	case tcell.KeyLeft:
+		if finder.cursorPos > 0 {
+			_, size := utf8.DecodeLastRuneInString(finder.input[:finder.cursorPos])
+			finder.cursorPos -= size
+		}
+	case tcell.KeyRight:
+		if finder.cursorPos < len(finder.input) {
+			_, size := utf8.DecodeRuneInString(finder.input[finder.cursorPos:])
+			finder.cursorPos += size
+		}
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if finder.cursorPos > 0 {
+			_, size := utf8.DecodeLastRuneInString(finder.input[:finder.cursorPos])
+			finder.input = finder.input[:finder.cursorPos-size] + finder.input[finder.cursorPos:]
+			finder.cursorPos -= size
+			finder.Selected = 0
+			finder.updateEntries()
+		}
+	case tcell.KeyRune:
+		finder.input = finder.input[:finder.cursorPos] + string(ev.Rune()) + finder.input[finder.cursorPos:]
+		finder.cursorPos += utf8.RuneLen(ev.Rune())
+		finder.Selected = 0
+		finder.updateEntries()
+	}
+}
+
+func getFileFinderDimensions(window *Window) (int, int, int, int) {
+	sizeX, sizeY := window.screen.Size()
+
+	width := min(sizeX-4, 80)
+	x1 := (sizeX - width) / 2
+	y1 := 0
+	if window.ShowTopMenu {
+		y1++
+	}
+
+	rows := max(min(len(currentFileFinder.Entries), fileFinderMaxEntries), 1)
+	y2 := min(y1+rows+3, sizeY-2)
+
+	return x1, y1, x1 + width - 1, y2
+}
+
+func drawFileFinder(window *Window) {
+	finder := currentFileFinder
+	if finder == nil {
+		return
+	}
+
+	screen := window.screen
+	finderStyle := CurrentStyle.GetStyle("dropdown")
+
+	x1, y1, x2, y2 := getFileFinderDimensions(window)
+	drawBox(screen, x1, y1, x2, y2, finderStyle)
+
+	// Draw input
+	drawText(screen, x1+1, y1+1, x2, y1+1, finderStyle, "> "+finder.input)
+
+	// Draw file count
+	count := fmt.Sprintf("%d/%d", len(finder.Entries), len(finder.files))
+	if finder.walking {
+		count = "… " + count
+	}
+	if x2-len([]rune(count))-1 > x1+3+utf8.RuneCountInString(finder.input) {
+		drawText(screen, x2-len([]rune(count))-1, y1+1, x2, y1+1, finderStyle, count)
+	}
+
+	// Draw separator
+	for x := x1 + 1; x < x2; x++ {
+		screen.SetContent(x, y1+2, tcell.RuneHLine, nil, finderStyle)
+	}
+	screen.SetContent(x1, y1+2, tcell.RuneLTee, nil, finderStyle)
+	screen.SetContent(x2, y1+2, tcell.RuneRTee, nil, finderStyle)
+
+	if len(finder.Entries) == 0 && !finder.walking {
+		drawText(screen, x1+2, y1+3, x2, y1+3, finderStyle, "No matching files")
+		return
+	}
+
+	// Draw entries
+	for i := finder.Offset; i < len(finder.Entries) && y1+3+i-finder.Offset < y2; i++ {
+		y := y1 + 3 + i - finder.Offset
+
+		style := finderStyle
+		if i == finder.Selected {
+			style = highlightStyle(style, CurrentStyle.DropdownSel)
+		}
+
+		for x := x1 + 1; x < x2; x++ {
+			screen.SetContent(x, y, ' ', nil, style)
+		}
+
+		// Truncate beginning of long paths
+		path := []rune(finder.Entries[i])
+		if len(path) > x2-x1-3 {
+			path = append([]rune{'…'}, path[len(path)-(x2-x1-4):]...)
+		}
+
+		drawText(screen, x1+2, y, x2, y, style, string(path))
+	}
+}
+
+func getFileFinderCursorPos(window *Window) (int, int) {
+	x1, y1, _, _ := getFileFinderDimensions(window)
+	return x1 + 3 + utf8.RuneCountInString(currentFileFinder.input[:currentFileFinder.cursorPos]), y1 + 1
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"strings"
+)
+
+// getIndentationWidth returns the width of the whitespace at the start of a line, with tabs expanded
+func getIndentationWidth(line string) int {
+	width := 0
+	for _, r := range line {
+		if r == ' ' {
+			width++
+		} else if r == '\t' {
+			width += Config.TabIndentation
+		} else {
+			break
+		}
+	}
+	return width
+}
+
+// GetFoldRanges returns the last line of the block that can be folded below each line. It is cached until the contents change.
+// Blocks between a bracket at the end of a line and its matching bracket are used in brace languages, otherwise blocks of more indented lines
+func (buffer *Buffer) GetFoldRanges() map[int]int {
+	if buffer.foldRanges != nil && buffer.foldRangesRevision == buffer.revision {
+		return buffer.foldRanges
+	}
+
+	lines := strings.Split(buffer.Contents, "\n")
+	ranges := make(map[int]int)
+
+	if language := buffer.GetLanguage(); language != nil && language.FoldBrackets {
+		mask := buffer.getCodeMask()
+		offsets := buffer.GetLineOffsets()
+		for line, lineStart := range offsets {
+			trimmed := strings.TrimRight(lines[line], " \t\r")
+			if trimmed == "" {
+				continue
+			}
+
+			pos := lineStart + len(trimmed) - 1
+			if _, ok := bracketPairs[buffer.Contents[pos]]; !ok || (mask != nil && !mask[pos]) {
+				continue
+			}
+
+			// Keep the line of the closing bracket visible
+			if match := buffer.FindMatchingBracket(pos); match != -1 {
+				if end := line + strings.Count(buffer.Contents[pos:match], "\n") - 1; end > line {
+					ranges[line] = end
+				}
+			}
+		}
+	}
+
+	for line, text := range lines {
+		if _, ok := ranges[line]; ok || strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		// Find the last following line that is more indented, ignoring empty lines
+		indentation := getIndentationWidth(text)
+		end := line
+		for i := line + 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "" {
+				continue
+			} else if getIndentationWidth(lines[i]) <= indentation {
+				break
+			}
+			end = i
+		}
+
+		if end > line {
+			ranges[line] = end
+		}
+	}
+
+	buffer.foldRanges = ranges
+	buffer.foldRangesRevision = buffer.revision
+
+	return ranges
+}
+
+// shiftFolds moves closed folds along with lines inserted or removed above them when the contents change.
+// Folds starting on changed lines are opened if the number of lines changed
+func (buffer *Buffer) shiftFolds(oldContents, contents string) {
+	// Find the changed part of the contents
+	prefix := 0
+	for prefix < len(oldContents) && prefix < len(contents) && oldContents[prefix] == contents[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldContents)-prefix && suffix < len(contents)-prefix && oldContents[len(oldContents)-1-suffix] == contents[len(contents)-1-suffix] {
+		suffix++
+	}
+
+	// Lines before firstChanged and from firstUnchanged on are unchanged
+	firstChanged := strings.Count(oldContents[:prefix], "\n")
+	changeEnd := len(oldContents) - suffix
+	firstUnchanged := strings.Count(oldContents[:changeEnd], "\n")
+	if changeEnd > 0 && oldContents[changeEnd-1] != '\n' {
+		firstUnchanged++
+	}
+	delta := strings.Count(contents[prefix:len(contents)-suffix], "\n") - strings.Count(oldContents[prefix:len(oldContents)-suffix], "\n")
+
+	folds := make(map[int]bool)
+	for line := range buffer.folds {
+		if line < firstChanged || delta == 0 {
+			folds[line] = true
+		} else if line >= firstUnchanged {
+			folds[line+delta] = true
+		}
+	}
+	buffer.folds = folds
+	buffer.foldsVersion++
+}
+
+// syncFolds removes closed folds of blocks that no longer exist after the contents changed
+func (buffer *Buffer) syncFolds() {
+	if buffer.foldsRevision == buffer.revision {
+		return
+	}
+
+	if len(buffer.folds) > 0 {
+		ranges := buffer.GetFoldRanges()
+		for line := range buffer.folds {
+			if _, ok := ranges[line]; !ok {
+				delete(buffer.folds, line)
+				buffer.foldsVersion++
+			}
+		}
+	}
+
+	buffer.foldsRevision = buffer.revision
+}
+
+// IsFolded returns whether the block below a line is folded
+func (buffer *Buffer) IsFolded(line int) bool {
+	buffer.syncFolds()
+	return buffer.folds[line]
+}
+
+// HasClosedFolds returns whether any lines are hidden by folds
+func (buffer *Buffer) HasClosedFolds() bool {
+	buffer.syncFolds()
+	return len(buffer.folds) > 0
+}
+
+// GetHiddenLines returns which lines are hidden by closed folds, or nil if no folds are closed.
+// It is cached until the contents or folds change and must not be modified
+func (buffer *Buffer) GetHiddenLines() []bool {
+	if !buffer.HasClosedFolds() {
+		return nil
+	}
+
+	if buffer.hiddenLines != nil && buffer.hiddenLinesRevision == buffer.revision && buffer.hiddenLinesFoldsVersion == buffer.foldsVersion {
+		return buffer.hiddenLines
+	}
+
+	ranges := buffer.GetFoldRanges()
+	hidden := make([]bool, buffer.GetLineCount())
+	for line := range buffer.folds {
+		for i := line + 1; i <= ranges[line] && i < len(hidden); i++ {
+			hidden[i] = true
+		}
+	}
+
+	buffer.hiddenLines = hidden
+	buffer.hiddenLinesRevision = buffer.revision
+	buffer.hiddenLinesFoldsVersion = buffer.foldsVersion
+
+	return hidden
+}
+
+// FoldLine closes the fold below a line and returns whether it exists
+func (buffer *Buffer) FoldLine(line int) bool {
+	buffer.syncFolds()
+	if _, ok := buffer.GetFoldRanges()[line]; !ok {
+		return false
+	}
+
+	if buffer.folds == nil {
+		buffer.folds = make(map[int]bool)
+	}
+	buffer.folds[line] = true
+	buffer.foldsVersion++
+
+	return true
+}
+
+// Fold closes the innermost open fold containing a line and returns its first line, or -1 if there is none
+func (buffer *Buffer) Fold(line int) int {
+	buffer.syncFolds()
+
+	start := -1
+	for foldStart, foldEnd := range buffer.GetFoldRanges() {
+		if foldStart <= line && line <= foldEnd && foldStart > start && !buffer.folds[foldStart] {
+			start = foldStart
+		}
+	}
+
+	if start != -1 {
+		buffer.FoldLine(start)
+	}
+
+	return start
+}
+
+// Unfold opens the closed folds containing a line, including the fold starting at it if includeStart is set.
+// It returns whether a fold was opened
+func (buffer *Buffer) Unfold(line int, includeStart bool) bool {
+	buffer.syncFolds()
+
+	ranges := buffer.GetFoldRanges()
+	opened := false
+	for foldStart := range buffer.folds {
+		if (foldStart < line || (includeStart && foldStart == line)) && line <= ranges[foldStart] {
+			delete(buffer.folds, foldStart)
+			opened = true
+		}
+	}
+	if opened {
+		buffer.foldsVersion++
+	}
+
+	return opened
+}
+
+func (buffer *Buffer) FoldAll() {
+	buffer.syncFolds()
+
+	buffer.folds = make(map[int]bool)
+	for line := range buffer.GetFoldRanges() {
+		buffer.folds[line] = true
+	}
+	buffer.foldsVersion++
+}
+
+func (buffer *Buffer) UnfoldAll() {
+	buffer.folds = nil
+	buffer.foldsVersion++
+}
+
+// moveCursorOutOfFolds moves the cursor to the first line of the fold hiding it
+func (window *Window) moveCursorOutOfFolds() {
+	hidden := window.CurrentBuffer.GetHiddenLines()
+
+	_, line := window.GetCursorPos2D()
+	if line >= len(hidden) || !hidden[line] {
+		window.SyncBufferOffset()
+		return
+	}
+
+	for line > 0 && hidden[line] {
+		line--
+	}
+	window.SetCursorPos2D(0, line)
+}
+
+// ToggleFold folds or unfolds the block below a line
+func (window *Window) ToggleFold(line int) {
+	buffer := window.CurrentBuffer
+
+	if buffer.IsFolded(line) {
+		buffer.Unfold(line, true)
+	} else {
+		buffer.FoldLine(line)
+	}
+
+	window.moveCursorOutOfFolds()
+}
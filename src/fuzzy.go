@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FuzzyMatch checks if all characters of pattern appear in str in order and returns a score for the match.
+// Consecutive characters, characters at the start of words and shorter strings score higher
+func FuzzyMatch(pattern, str string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+
+	patternRunes := []rune(strings.ToLower(pattern))
+	strRunes := []rune(str)
+
+	score := 0
+	patternIndex := 0
+	lastMatch := -1
+	for i, r := range strRunes {
+		if patternIndex >= len(patternRunes) {
+			break
+		}
+
+		if unicode.ToLower(r) != patternRunes[patternIndex] {
+			continue
+		}
+
+		score += 1
+
+		// Consecutive characters
+		if lastMatch != -1 && lastMatch == i-1 {
+			score += 5
+		}
+
+		// Start of string or word
+		if i == 0 {
+			score += 10
+		} else if prev := strRunes[i-1]; prev == '-' || prev == '_' || prev == ' ' || prev == '/' || prev == '.' || (unicode.IsLower(prev) && unicode.IsUpper(r)) {
+			score += 8
+		}
+
+		lastMatch = i
+		patternIndex++
+	}
+
+	if patternIndex < len(patternRunes) {
+		return 0, false
+	}
+
+	// Prefer shorter strings
+	score -= len(strRunes) / 8
+
+	return score, true
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type gitIgnorePattern struct {
+	base     string
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// GitIgnore matches paths relative to Root against the .gitignore files found in the directory tree
+type GitIgnore struct {
+	Root     string
+	patterns []gitIgnorePattern
+	loaded   map[string]bool
+}
+
+func CreateGitIgnore(root string) *GitIgnore {
+	return &GitIgnore{
+		Root:     root,
+		patterns: make([]gitIgnorePattern, 0),
+		loaded:   make(map[string]bool),
+	}
+}
+
+// loadDir reads the .gitignore file of a directory relative to the root once
+func (ignore *GitIgnore) loadDir(dir string) {
+	if ignore.loaded[dir] {
+		return
+	}
+	ignore.loaded[dir] = true
+
+	data, err := os.ReadFile(filepath.Join(ignore.Root, dir, ".gitignore"))
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimRight(line, " ")
+
+		pattern := gitIgnorePattern{base: dir}
+
+		if strings.HasPrefix(line, "!") {
+			pattern.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, "\\") {
+			line = line[1:]
+		}
+
+		if strings.HasSuffix(line, "/") {
+			pattern.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+
+		// Patterns containing a slash are relative to the .gitignore location
+		if strings.Contains(line, "/") {
+			pattern.anchored = true
+			line = strings.TrimLeft(line, "/")
+		}
+
+		if line == "" {
+			continue
+		}
+
+		pattern.segments = strings.Split(line, "/")
+		ignore.patterns = append(ignore.patterns, pattern)
+	}
+}
+
+// IsIgnored checks if a path relative to the root is ignored. Paths inside the .git directory are always ignored
+func (ignore *GitIgnore) IsIgnored(relPath string, isDir bool) bool {
+	relPath = filepath.ToSlash(filepath.Clean(relPath))
+	if relPath == "." {
+		return false
+	}
+
+	segments := strings.Split(relPath, "/")
+	if segments[0] == ".git" {
+		return true
+	}
+
+	// Load .gitignore files of all parent directories
+	ignore.loadDir(".")
+	for i := 1; i < len(segments); i++ {
+		ignore.loadDir(path.Join(segments[:i]...))
+	}
+
+	// Last matching pattern decides
+	ignored := false
+	for _, pattern := range ignore.patterns {
+		if pattern.dirOnly && !isDir {
+			continue
+		}
+
+		relSegments := segments
+		if pattern.base != "." {
+			baseSegments := strings.Split(pattern.base, "/")
+			if len(baseSegments) >= len(segments) || strings.Join(segments[:len(baseSegments)], "/") != pattern.base {
+				continue
+			}
+			relSegments = segments[len(baseSegments):]
+		}
+
+		matched := false
+		if pattern.anchored {
+			matched = matchGlobSegments(pattern.segments, relSegments)
+		} else {
+			matched, _ = path.Match(pattern.segments[0], relSegments[len(relSegments)-1])
+		}
+
+		if matched {
+			ignored = !pattern.negate
+		}
+	}
+
+	return ignored
+}
+
+// matchGlobSegments matches path segments against pattern segments, where "**" matches any number of segments
+func matchGlobSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchGlobSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+
+	return matchGlobSegments(pattern[1:], segments[1:])
+}
@@ -2,15 +2,17 @@ module Typer
 
 go 1.24
 
-require github.com/gdamore/tcell/v2 v2.8.1
+require (
+	github.com/gdamore/tcell/v2 v2.8.1
+	github.com/lucasb-eyer/go-colorful v1.2.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/gdamore/encoding v1.0.1 // indirect
-	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-runewidth v0.0.16 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	golang.org/x/sys v0.33.0 // indirect
 	golang.org/x/term v0.32.0 // indirect
 	golang.org/x/text v0.26.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,444 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type GrepSearch struct {
+	Pattern     string
+	Replacement string
+	Replace     bool
+
+	matcher *regexp.Regexp
+	literal bool
+	buffer  *Buffer
+	root    string
+	done    chan struct{}
+
+	matches []GrepMatch
+	files   []string
+	running bool
+}
+
+type GrepMatch struct {
+	Path   string
+	Line   int
+	Column int
+	Text   string
+}
+
+const grepResultsBufferName = "Search Results"
+const grepMaxLineLength = 200
+
+var currentGrepSearch *GrepSearch
+
+// isGrepRegexPattern returns whether a pattern is a regular expression surrounded by slashes
+func isGrepRegexPattern(pattern string) bool {
+	return len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/")
+}
+
+// compileGrepPattern compiles a pattern surrounded by slashes as a regular expression and any other pattern as a literal string
+func compileGrepPattern(pattern string) (*regexp.Regexp, error) {
+	if isGrepRegexPattern(pattern) {
+		return regexp.Compile(pattern[1 : len(pattern)-1])
+	}
+	return regexp.Compile(regexp.QuoteMeta(pattern))
+}
+
+// StartGrep searches all files under the project root and writes the matches to the search results buffer.
+// If replace is true, a preview of the replacements is shown and the user is asked to apply them once the search is done
+func StartGrep(window *Window, pattern string, replacement string, replace bool) {
+	matcher, err := compileGrepPattern(pattern)
+	if err != nil {
+		PrintError(window, fmt.Sprintf("Invalid regular expression: %s", err))
+		return
+	}
+
+	CancelGrep()
+
+	// Create or reuse results buffer
+	buffer := GetBufferByName(grepResultsBufferName)
+	if buffer == nil {
+		buffer, err = CreateBuffer(grepResultsBufferName)
+		if err != nil {
+			PrintError(window, fmt.Sprintf("Could not create results buffer: %s", err))
+			return
+		}
+	}
+	buffer.canSave = false
+	buffer.readOnly = true
+	buffer.Selection = nil
+	buffer.CollapseCursors()
+	buffer.BlockSelection = nil
+	buffer.CursorPos = 0
+	buffer.OffsetX, buffer.OffsetY = 0, 0
+
+	if replace {
+		buffer.SetContents(fmt.Sprintf("Replacing '%s' with '%s':\n", pattern, replacement))
+	} else {
+		buffer.SetContents(fmt.Sprintf("Search results for '%s':\n", pattern))
+	}
+
+	window.CurrentBuffer = buffer
+	window.CursorMode = CursorModeBuffer
+
+	search := &GrepSearch{
+		Pattern:     pattern,
+		Replacement: replacement,
+		Replace:     replace,
+		matcher:     matcher,
+		literal:     !isGrepRegexPattern(pattern),
+		buffer:      buffer,
+		root:        fileBrowser.Root,
+		done:        make(chan struct{}),
+		matches:     make([]GrepMatch, 0),
+		files:       make([]string, 0),
+		running:     true,
+	}
+	currentGrepSearch = search
+
+	// Search unsaved contents of open buffers instead of the files on disk
+	openContents := make(map[string]string)
+	for _, b := range Buffers {
+		if b.filename != "" {
+			openContents[b.filename] = b.Contents
+		}
+	}
+
+	go search.run(window, openContents)
+}
+
+// CancelGrep stops the running search
+func CancelGrep() bool {
+	search := currentGrepSearch
+	if search == nil || !search.running {
+		return false
+	}
+
+	close(search.done)
+	search.running = false
+	currentGrepSearch = nil
+
+	search.buffer.SetContents(search.buffer.Contents + "Search cancelled.\n")
+
+	return true
+}
+
+// run searches files on multiple goroutines and adds the results to the results buffer in batches
+func (search *GrepSearch) run(window *Window, openContents map[string]string) {
+	paths := make(chan string)
+	results := make(chan []GrepMatch)
+
+	// Walk files
+	go func() {
+		walkFiles(search.root, search.done, func(relPath string) {
+			select {
+			case paths <- relPath:
+			case <-search.done:
+			}
+		})
+		close(paths)
+	}()
+
+	// Search files
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for relPath := range paths {
+				absPath := filepath.Join(search.root, relPath)
+
+				contents, ok := openContents[absPath]
+				if !ok {
+					data, err := os.ReadFile(absPath)
+					if err != nil {
+						continue
+					}
+					contents = string(data)
+				}
+
+				matches := search.searchContents(relPath, contents)
+				if len(matches) == 0 {
+					continue
+				}
+
+				select {
+				case results <- matches:
+				case <-search.done:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Collect results
+	batch := make([][]GrepMatch, 0)
+	lastBatch := time.Now()
+
+	sendBatch := func(finished bool) {
+		files := batch
+		batch = make([][]GrepMatch, 0)
+		lastBatch = time.Now()
+
+		window.RunOnMainLoop(func() {
+			if currentGrepSearch != search {
+				return
+			}
+
+			// Stop searching once the results buffer is closed
+			if !slices.Contains(Buffers, search.buffer) {
+				CancelGrep()
+				return
+			}
+
+			search.addResults(files)
+			if finished {
+				search.finish(window)
+			}
+		})
+	}
+
+	for matches := range results {
+		batch = append(batch, matches)
+		if time.Since(lastBatch) > 50*time.Millisecond {
+			sendBatch(false)
+		}
+	}
+
+	select {
+	case <-search.done:
+	default:
+		sendBatch(true)
+	}
+}
+
+// searchContents returns the matches of the search pattern in each line of a file
+func (search *GrepSearch) searchContents(relPath string, contents string) []GrepMatch {
+	matches := make([]GrepMatch, 0)
+
+	for i, line := range strings.Split(contents, "\n") {
+		for _, index := range search.matcher.FindAllStringIndex(line, -1) {
+			// Skip empty matches
+			if index[0] == index[1] {
+				continue
+			}
+
+			matches = append(matches, GrepMatch{
+				Path:   relPath,
+				Line:   i + 1,
+				Column: index[0] + 1,
+				Text:   line,
+			})
+
+			// Replacements are previewed per line
+			if search.Replace {
+				break
+			}
+		}
+	}
+
+	return matches
+}
+
+func (search *GrepSearch) addResults(files [][]GrepMatch) {
+	builder := strings.Builder{}
+
+	for _, matches := range files {
+		search.files = append(search.files, matches[0].Path)
+		search.matches = append(search.matches, matches...)
+
+		for _, match := range matches {
+			builder.WriteString(fmt.Sprintf("%s:%d:%d: %s\n", match.Path, match.Line, match.Column, truncateGrepLine(match.Text)))
+
+			if search.Replace {
+				replaced := search.replaceLine(match.Text)
+				builder.WriteString(fmt.Sprintf("    -> %s\n", truncateGrepLine(replaced)))
+			}
+		}
+	}
+
+	search.buffer.SetContents(search.buffer.Contents + builder.String())
+}
+
+func (search *GrepSearch) finish(window *Window) {
+	search.running = false
+	currentGrepSearch = nil
+
+	summary := fmt.Sprintf("%d matches in %d files.", len(search.matches), len(search.files))
+	if search.Replace {
+		summary = fmt.Sprintf("%d matching lines in %d files.", len(search.matches), len(search.files))
+	}
+	search.buffer.SetContents(search.buffer.Contents + summary + "\n")
+
+	if !search.Replace || len(search.matches) == 0 {
+		PrintMessage(window, summary)
+		return
+	}
+
+	prompt := fmt.Sprintf("Replace in %d lines of %d files [y\\N]:", len(search.matches), len(search.files))
+	RequestInput(window, prompt, "", "", func(input string, cancelled bool) {
+		if cancelled || (strings.ToLower(input) != "y" && strings.ToLower(input) != "yes") {
+			PrintMessage(window, "Replace cancelled.")
+			return
+		}
+
+		search.applyReplacements(window)
+	})
+}
+
+// applyReplacements replaces the previewed matches in open buffers and in the files on disk.
+// Lines that changed since the preview are skipped
+func (search *GrepSearch) applyReplacements(window *Window) {
+	fileMatches := make(map[string][]GrepMatch)
+	for _, match := range search.matches {
+		fileMatches[match.Path] = append(fileMatches[match.Path], match)
+	}
+
+	replacedFiles, replacedLines, skippedLines := 0, 0, 0
+	failedFiles := make([]string, 0)
+
+	for _, relPath := range search.files {
+		absPath := filepath.Join(search.root, relPath)
+
+		// Replace in open buffer
+		if buffer := GetBufferByFilename(absPath); buffer != nil {
+			contents, replaced, skipped := search.replaceLines(buffer.Contents, fileMatches[relPath])
+			skippedLines += skipped
+			if replaced == 0 {
+				continue
+			}
+
+			buffer.SetContents(contents)
+			buffer.Selection = nil
+			buffer.CollapseCursors()
+			buffer.BlockSelection = nil
+			buffer.CursorPos = min(buffer.CursorPos, len(buffer.Contents))
+			replacedFiles++
+			replacedLines += replaced
+			continue
+		}
+
+		// Replace in file
+		stat, err := os.Stat(absPath)
+		if err != nil {
+			failedFiles = append(failedFiles, relPath)
+			continue
+		}
+
+		data, err := os.ReadFile(absPath)
+		if err != nil {
+			failedFiles = append(failedFiles, relPath)
+			continue
+		}
+
+		contents, replaced, skipped := search.replaceLines(string(data), fileMatches[relPath])
+		skippedLines += skipped
+		if replaced == 0 {
+			continue
+		}
+
+		err = os.WriteFile(absPath, []byte(contents), stat.Mode().Perm())
+		if err != nil {
+			failedFiles = append(failedFiles, relPath)
+			continue
+		}
+
+		replacedFiles++
+		replacedLines += replaced
+	}
+
+	summary := fmt.Sprintf("Replaced %d lines in %d files.", replacedLines, replacedFiles)
+	if skippedLines > 0 {
+		summary += fmt.Sprintf(" Skipped %d lines changed since the search.", skippedLines)
+	}
+
+	if len(failedFiles) > 0 {
+		PrintError(window, fmt.Sprintf("%s Could not write: %s", summary, strings.Join(failedFiles, ", ")))
+	} else if skippedLines > 0 {
+		PrintWarning(window, summary)
+	} else {
+		PrintMessage(window, summary)
+	}
+}
+
+// replaceLines applies the replacement to the previewed lines of a file, like the preview.
+// It returns the new contents and the number of replaced lines and of lines skipped because they no longer match the preview
+func (search *GrepSearch) replaceLines(contents string, matches []GrepMatch) (string, int, int) {
+	lines := strings.Split(contents, "\n")
+
+	replaced, skipped := 0, 0
+	for _, match := range matches {
+		if match.Line > len(lines) || lines[match.Line-1] != match.Text {
+			skipped++
+			continue
+		}
+
+		lines[match.Line-1] = search.replaceLine(match.Text)
+		replaced++
+	}
+
+	return strings.Join(lines, "\n"), replaced, skipped
+}
+
+// replaceLine replaces the matches in a line. The replacement of literal searches is inserted as is, while regular expressions may refer to groups with $1 or ${name}
+func (search *GrepSearch) replaceLine(line string) string {
+	if search.literal {
+		return search.matcher.ReplaceAllLiteralString(line, search.Replacement)
+	}
+	return search.matcher.ReplaceAllString(line, search.Replacement)
+}
+
+func truncateGrepLine(line string) string {
+	line = strings.TrimRight(line, "\r")
+	if runes := []rune(line); len(runes) > grepMaxLineLength {
+		return string(runes[:grepMaxLineLength]) + "..."
+	}
+	return line
+}
+
+// OpenLocationAtCursor opens the file location on the line under the cursor in a results buffer
+func (window *Window) OpenLocationAtCursor() bool {
+	buffer := window.CurrentBuffer
+
+	lineStart := strings.LastIndex(buffer.Contents[:buffer.CursorPos], "\n") + 1
+	lineEnd := strings.IndexByte(buffer.Contents[lineStart:], '\n')
+	if lineEnd == -1 {
+		lineEnd = len(buffer.Contents)
+	} else {
+		lineEnd += lineStart
+	}
+	line := buffer.Contents[lineStart:lineEnd]
+
+	// Location ends at the first ": " following a line and column number
+	location := ""
+	for i := 0; i < len(line); i++ {
+		if strings.HasPrefix(line[i:], ": ") {
+			if _, lineNumber, column := ParseFileLocation(line[:i]); lineNumber > 0 && column > 0 {
+				location = line[:i]
+				break
+			}
+		}
+	}
+	if location == "" {
+		return false
+	}
+
+	path, lineNumber, column := ParseFileLocation(location)
+
+	return OpenFileLocation(window, path, lineNumber, column)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func newGrepTestSearch(t *testing.T, pattern, replacement string) *GrepSearch {
+	matcher, err := compileGrepPattern(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &GrepSearch{
+		Pattern:     pattern,
+		Replacement: replacement,
+		Replace:     true,
+		matcher:     matcher,
+		literal:     !isGrepRegexPattern(pattern),
+		root:        t.TempDir(),
+	}
+}
+
+func TestGrepReplaceDollarSign(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	window := &Window{screen: screen, CurrentBuffer: &Buffer{}}
+
+	tests := []struct {
+		pattern, replacement string
+		contents, expected   string
+	}{
+		{"price", "$cost", "price = 5\n", "$cost = 5\n"},
+		{"a.b", "${1}x", "a.b axb\n", "${1}x axb\n"},
+		{"/(p)rice/", "${1}ost", "price = 5\n", "post = 5\n"},
+	}
+
+	for _, test := range tests {
+		search := newGrepTestSearch(t, test.pattern, test.replacement)
+
+		path := filepath.Join(search.root, "file.txt")
+		if err := os.WriteFile(path, []byte(test.contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		search.buffer = &Buffer{}
+		search.addResults([][]GrepMatch{search.searchContents("file.txt", test.contents)})
+
+		// Preview shows the text that is written
+		preview := "    -> " + strings.TrimSuffix(test.expected, "\n") + "\n"
+		if !strings.HasSuffix(search.buffer.Contents, preview) {
+			t.Errorf("replacing %s with %s: expected preview %q, got %q", test.pattern, test.replacement, preview, search.buffer.Contents)
+		}
+
+		search.applyReplacements(window)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != test.expected {
+			t.Errorf("replacing %s with %s: expected %q, got %q", test.pattern, test.replacement, test.expected, data)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const helpBufferName = "Help"
+
+// Set while describe-key waits for a key press
+var describeKeyPending = false
+
+// ShowHelp opens a read-only buffer listing commands, key bindings, config options and styles
+func ShowHelp(window *Window) {
+	buffer := GetBufferByName(helpBufferName)
+	if buffer == nil {
+		var err error
+		buffer, err = CreateBuffer(helpBufferName)
+		if err != nil {
+			PrintError(window, fmt.Sprintf("Could not create help buffer: %s", err))
+			return
+		}
+	}
+	buffer.canSave = false
+	buffer.readOnly = true
+
+	builder := strings.Builder{}
+	builder.WriteString("Typer Help\n")
+	builder.WriteString("==========\n\n")
+	builder.WriteString("Run commands from the command palette or by their key bindings.\n")
+	builder.WriteString("Use describe-key to find out what a key is bound to.\n\n")
+
+	writeHelpCommands(&builder)
+	writeHelpKeybindings(&builder)
+	writeHelpConfig(&builder)
+	writeHelpStyles(&builder)
+
+	buffer.SetContents(builder.String())
+	buffer.Selection = nil
+	buffer.CollapseCursors()
+	buffer.BlockSelection = nil
+	buffer.OffsetX, buffer.OffsetY = 0, 0
+
+	window.CurrentBuffer = buffer
+	window.CursorMode = CursorModeBuffer
+	window.SetCursorPos(0)
+}
+
+func writeHelpCommands(builder *strings.Builder) {
+	names := make([]string, 0, len(commands))
+	width := 0
+	for name := range commands {
+		names = append(names, name)
+		width = max(width, len(name))
+	}
+	slices.Sort(names)
+
+	builder.WriteString("Commands\n")
+	builder.WriteString("--------\n")
+	for _, name := range names {
+		line := fmt.Sprintf("  %-*s  %s", width, name, commands[name].description)
+		if keybinding := GetCommandKeybinding(name); keybinding != "" {
+			line += fmt.Sprintf(" (%s)", keybinding)
+		}
+		builder.WriteString(line + "\n")
+	}
+	builder.WriteString("\n")
+}
+
+func writeHelpKeybindings(builder *strings.Builder) {
+	builder.WriteString("Key bindings\n")
+	builder.WriteString("------------\n")
+
+	for mode := CursorModeDisabled; int(mode) < len(CursorModeNames); mode++ {
+		bindings := make([]Keybinding, 0)
+		width := 0
+		for _, keybinding := range Keybindings.Keybindings {
+			if slices.Contains(keybinding.GetCursorModes(), mode) {
+				bindings = append(bindings, keybinding)
+				width = max(width, len(keybinding.Keybinding))
+			}
+		}
+
+		if len(bindings) == 0 {
+			continue
+		}
+
+		builder.WriteString(fmt.Sprintf("  %s:\n", CursorModeNames[mode]))
+		for _, keybinding := range bindings {
+			builder.WriteString(fmt.Sprintf("    %-*s  %s\n", width, keybinding.Keybinding, keybinding.Command))
+		}
+	}
+	builder.WriteString("\n")
+}
+
+func writeHelpConfig(builder *strings.Builder) {
+	builder.WriteString("Config options\n")
+	builder.WriteString("--------------\n")
+
+	// Get option names from yaml tags
+	var writeFields func(prefix string, value reflect.Value)
+	writeFields = func(prefix string, value reflect.Value) {
+		for i := 0; i < value.NumField(); i++ {
+			name, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+
+			field := value.Field(i)
+			if field.Kind() == reflect.Struct {
+				writeFields(prefix+name+".", field)
+				continue
+			}
+
+			builder.WriteString(fmt.Sprintf("  %s%s: %v\n", prefix, name, field.Interface()))
+		}
+	}
+	writeFields("", reflect.ValueOf(Config))
+	builder.WriteString("\n")
+}
+
+func writeHelpStyles(builder *strings.Builder) {
+	names := make([]string, 0, len(AvailableStyles))
+	for name := range AvailableStyles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	builder.WriteString("Styles\n")
+	builder.WriteString("------\n")
+	for _, name := range names {
+		style := AvailableStyles[name]
+
+		marker := " "
+		if name == CurrentStyle.Name {
+			marker = "*"
+		}
+		builder.WriteString(fmt.Sprintf("%s %s (%s): %s\n", marker, name, style.StyleType, style.Description))
+	}
+}
+
+// StartDescribeKey makes the next key press show what it is bound to instead of running it
+func StartDescribeKey(window *Window) {
+	describeKeyPending = true
+	PrintMessage(window, "Press a key to describe...")
+}
+
+func describeKey(window *Window, ev *tcell.EventKey) {
+	describeKeyPending = false
+
+	bindings := make([]string, 0)
+	for _, keybinding := range Keybindings.Keybindings {
+		if !keybinding.IsPressed(ev) {
+			continue
+		}
+
+		modes := make([]string, 0)
+		for _, mode := range keybinding.GetCursorModes() {
+			modes = append(modes, CursorModeNames[mode])
+		}
+		bindings = append(bindings, fmt.Sprintf("'%s' in %s", keybinding.Command, strings.Join(modes, ", ")))
+	}
+
+	keyName := ev.Name()
+	if ev.Key() == tcell.KeyRune && ev.Modifiers() == tcell.ModNone {
+		keyName = string(ev.Rune())
+	}
+
+	if len(bindings) == 0 {
+		PrintMessage(window, fmt.Sprintf("%s is not bound.", keyName))
+	} else {
+		PrintMessage(window, fmt.Sprintf("%s runs %s.", keyName, strings.Join(bindings, "; ")))
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"gopkg.in/yaml.v3"
+	"os"
+	"path"
+	"slices"
+)
+
+const maxInputHistoryEntries = 100
+
+var inputHistory = make(map[string][]string)
+
+func getInputHistoryPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(homeDir, ".local/state/typer/input_history.yml"), nil
+}
+
+func readInputHistory() {
+	historyPath, err := getInputHistoryPath()
+	if err != nil {
+		return
+	}
+
+	data, err := os.ReadFile(historyPath)
+	if err != nil {
+		return
+	}
+
+	// Ignore invalid history files
+	history := make(map[string][]string)
+	if err := yaml.Unmarshal(data, &history); err != nil {
+		return
+	}
+
+	inputHistory = history
+}
+
+func writeInputHistory() error {
+	historyPath, err := getInputHistoryPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(path.Dir(historyPath), 0755); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(inputHistory)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(historyPath, data, 0644)
+}
+
+// AddToInputHistory appends input to the history of the given prompt kind, moving it to the end if it already exists
+func AddToInputHistory(kind, input string) {
+	if kind == "" || input == "" {
+		return
+	}
+
+	history := inputHistory[kind]
+	if i := slices.Index(history, input); i != -1 {
+		history = DeleteFromSlice(history, i)
+	}
+	history = append(history, input)
+
+	if len(history) > maxInputHistoryEntries {
+		history = history[len(history)-maxInputHistoryEntries:]
+	}
+
+	inputHistory[kind] = history
+}
@@ -2,33 +2,234 @@ package main
 
 import (
 	"github.com/gdamore/tcell/v2"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type TyperInputRequest struct {
-	Text         string
-	input        string
-	cursorPos    int
-	inputChannel chan string
+	Text      string
+	Kind      string
+	input     string
+	cursorPos int
+	callback  InputCallback
+
+	historyIndex int
+	draft        string
+
+	completions     []string
+	completionIndex int
 }
 
-var inputHistory = make([]string, 0)
+// InputCallback receives the input of a prompt once it is submitted or cancelled.
+// It always runs on the event loop, so it may access editor state and open further prompts
+type InputCallback func(input string, cancelled bool)
+
+type InputCompleter func(window *Window, input string) []string
+
+var inputCompleters = make(map[string]InputCompleter)
 var currentInputRequest *TyperInputRequest
 
-func RequestInput(window *Window, text string, defaultInput string) chan string {
-	request := &TyperInputRequest{
+// RegisterInputCompleter sets the function used to complete input of the given kind when tab is pressed
+func RegisterInputCompleter(kind string, completer InputCompleter) {
+	inputCompleters[kind] = completer
+}
+
+// RequestInput shows a prompt in the input bar. The callback is run once the prompt is submitted or cancelled.
+// A prompt that is still open is cancelled
+func RequestInput(window *Window, text string, defaultInput string, kind string, callback InputCallback) {
+	if currentInputRequest != nil {
+		CancelInput(window)
+	}
+
+	currentInputRequest = &TyperInputRequest{
 		Text:         text,
+		Kind:         kind,
 		input:        defaultInput,
 		cursorPos:    len(defaultInput),
-		inputChannel: make(chan string),
+		callback:     callback,
+		historyIndex: -1,
 	}
 
-	currentInputRequest = request
-
 	window.CursorMode = CursorModeInputBar
+}
+
+// CancelInput closes the current prompt and tells its caller that it was aborted
+func CancelInput(window *Window) {
+	request := currentInputRequest
+	if request == nil {
+		return
+	}
+
+	currentInputRequest = nil
+	window.CursorMode = CursorModeBuffer
+
+	window.RunOnMainLoop(func() {
+		request.callback("", true)
+	})
+}
+
+// submitInput closes the current prompt and passes its input to the caller
+func submitInput(window *Window) {
+	request := currentInputRequest
+	if request == nil {
+		return
+	}
+
+	AddToInputHistory(request.Kind, request.input)
+
+	currentInputRequest = nil
+	window.CursorMode = CursorModeBuffer
+
+	window.RunOnMainLoop(func() {
+		request.callback(request.input, false)
+	})
+}
+
+func (request *TyperInputRequest) setInput(input string) {
+	request.input = input
+	request.cursorPos = len(input)
+}
+
+// recallHistory replaces the input with an older (negative delta) or newer (positive delta) entry of the input history
+func (request *TyperInputRequest) recallHistory(delta int) {
+	history := inputHistory[request.Kind]
+	if request.Kind == "" || len(history) == 0 {
+		return
+	}
+
+	// Save typed input before browsing history
+	if request.historyIndex == -1 {
+		if delta > 0 {
+			return
+		}
+		request.draft = request.input
+		request.historyIndex = len(history)
+	}
+
+	request.historyIndex = min(max(request.historyIndex+delta, 0), len(history))
+
+	if request.historyIndex == len(history) {
+		request.historyIndex = -1
+		request.setInput(request.draft)
+	} else {
+		request.setInput(history[request.historyIndex])
+	}
+}
+
+// complete completes the input using the completer registered for the request kind.
+// Repeated presses cycle through the available completions
+func (request *TyperInputRequest) complete(window *Window) {
+	if request.completions != nil {
+		request.completionIndex = (request.completionIndex + 1) % len(request.completions)
+		request.setInput(request.completions[request.completionIndex])
+		return
+	}
+
+	completer, ok := inputCompleters[request.Kind]
+	if !ok {
+		return
+	}
+
+	completions := completer(window, request.input)
+	if len(completions) == 0 {
+		return
+	} else if len(completions) == 1 {
+		request.setInput(completions[0])
+		return
+	}
+
+	// Complete longest common prefix first
+	prefix := completions[0]
+	for _, completion := range completions[1:] {
+		for !strings.HasPrefix(completion, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	if len(prefix) > len(request.input) {
+		request.setInput(prefix)
+	} else {
+		request.completions = completions
+		request.completionIndex = 0
+		request.setInput(completions[0])
+	}
+}
+
+func handleInputBarKey(window *Window, ev *tcell.EventKey) {
+	request := currentInputRequest
+
+	// Reset completion cycling after any other key
+	if ev.Key() != tcell.KeyTab {
+		request.completions = nil
+	}
+
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		CancelInput(window)
+	case tcell.KeyEnter:
+		submitInput(window)
+	case tcell.KeyTab:
+		request.complete(window)
+	case tcell.KeyUp:
+		request.recallHistory(-1)
+	case tcell.KeyDown:
+		request.recallHistory(1)
+	case tcell.KeyLeft:
+		if request.cursorPos > 0 {
+			_, size := utf8.DecodeLastRuneInString(request.input[:request.cursorPos])
+			request.cursorPos -= size
+		}
+	case tcell.KeyRight:
+		if request.cursorPos < len(request.input) {
+			_, size := utf8.DecodeRuneInString(request.input[request.cursorPos:])
+			request.cursorPos += size
+		}
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		request.cursorPos = 0
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		request.cursorPos = len(request.input)
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if request.cursorPos > 0 {
+			_, size := utf8.DecodeLastRuneInString(request.input[:request.cursorPos])
+			request.input = request.input[:request.cursorPos-size] + request.input[request.cursorPos:]
+			request.cursorPos -= size
+		}
+	case tcell.KeyDelete:
+		if request.cursorPos < len(request.input) {
+			_, size := utf8.DecodeRuneInString(request.input[request.cursorPos:])
+			request.input = request.input[:request.cursorPos] + request.input[request.cursorPos+size:]
+		}
+	case tcell.KeyCtrlW:
+		// Delete word before cursor
+		start := strings.TrimRightFunc(request.input[:request.cursorPos], unicode.IsSpace)
+		start = strings.TrimRightFunc(start, func(r rune) bool {
+			return !unicode.IsSpace(r)
+		})
+		request.input = start + request.input[request.cursorPos:]
+		request.cursorPos = len(start)
+	case tcell.KeyRune:
+		request.input = request.input[:request.cursorPos] + string(ev.Rune()) + request.input[request.cursorPos:]
+		request.cursorPos += utf8.RuneLen(ev.Rune())
+	}
+}
+
+// getInputBarScroll returns how many characters of the input are scrolled out of view to keep the cursor visible
+func getInputBarScroll(window *Window) int {
+	sizeX, _ := window.screen.Size()
+
+	available := sizeX - len(currentInputRequest.Text) - 2
+	cursor := utf8.RuneCountInString(currentInputRequest.input[:currentInputRequest.cursorPos])
+
+	return max(cursor-available, 0)
+}
+
+func getInputBarCursorPos(window *Window) (int, int) {
+	_, sizeY := window.screen.Size()
 
-	_ = window.screen.PostEvent(tcell.NewEventInterrupt(nil))
+	cursor := utf8.RuneCountInString(currentInputRequest.input[:currentInputRequest.cursorPos])
 
-	return request.inputChannel
+	return len(currentInputRequest.Text) + 1 + cursor - getInputBarScroll(window), sizeY - 1
 }
 
 func drawInputBar(window *Window) {
@@ -38,7 +239,7 @@ func drawInputBar(window *Window) {
 
 	screen := window.screen
 
-	inputBarStyle := tcell.StyleDefault.Background(CurrentStyle.InputBarBg).Foreground(CurrentStyle.InputBarFg)
+	inputBarStyle := CurrentStyle.GetStyle("input_bar")
 
 	sizeX, sizeY := screen.Size()
 
@@ -52,7 +253,8 @@ func drawInputBar(window *Window) {
 	for x := 0; x < len(currentInputRequest.Text); x++ {
 		screen.SetContent(x, sizeY-1, rune(currentInputRequest.Text[x]), nil, inputBarStyle)
 	}
-	for x := 0; x < len(currentInputRequest.input); x++ {
-		screen.SetContent(x+len(currentInputRequest.Text)+1, sizeY-1, rune(currentInputRequest.input[x]), nil, inputBarStyle)
+	input := []rune(currentInputRequest.input)[getInputBarScroll(window):]
+	for x := 0; x < len(input); x++ {
+		screen.SetContent(x+len(currentInputRequest.Text)+1, sizeY-1, input[x], nil, inputBarStyle)
 	}
 }
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// TestInputCallbacksRunOnMainLoop submits and cancels prompts while other goroutines queue callbacks.
+// Run with -race to check that callbacks only touch buffers on the event loop
+func TestInputCallbacksRunOnMainLoop(t *testing.T) {
+	readConfig()
+	initCommands()
+	Keybindings = TyperKeybindings{}
+
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(80, 24)
+
+	buffer := &Buffer{Name: "test", canSave: true}
+	window := &Window{screen: screen, CurrentBuffer: buffer}
+	currentInputRequest = nil
+
+	submitted, cancelled := "", false
+	RequestInput(window, "First:", "", "", func(input string, wasCancelled bool) {
+		submitted = input
+		buffer.SetContents(buffer.Contents + input)
+
+		RequestInput(window, "Second:", "", "", func(input string, wasCancelled bool) {
+			cancelled = wasCancelled
+			buffer.SetContents(buffer.Contents + "!")
+		})
+	})
+
+	for _, r := range "hi" {
+		screen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+	}
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	screen.InjectKey(tcell.KeyRune, 'x', tcell.ModNone)
+	screen.InjectKey(tcell.KeyEscape, 0, tcell.ModNone)
+
+	// Queue callbacks from other goroutines, like background searches do
+	const backgroundCallbacks = 50
+	backgroundRuns := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < backgroundCallbacks; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			window.RunOnMainLoop(func() {
+				backgroundRuns++
+				buffer.SetContents(buffer.Contents + ".")
+			})
+		}()
+	}
+
+	// Wake up the event loop regularly, so a missing callback fails the test instead of blocking it
+	deadline := time.Now().Add(5 * time.Second)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				screen.PostEvent(tcell.NewEventInterrupt(nil))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for !cancelled || backgroundRuns < backgroundCallbacks {
+		if time.Now().After(deadline) {
+			t.Fatalf("callbacks did not run before the deadline (cancelled: %t, background callbacks: %d)", cancelled, backgroundRuns)
+		}
+
+		window.Draw()
+		window.ProcessEvents()
+	}
+	wg.Wait()
+
+	if submitted != "hi" {
+		t.Fatalf("expected submitted input (hi), got (%s)", submitted)
+	}
+	if currentInputRequest != nil || window.CursorMode != CursorModeBuffer {
+		t.Fatal("expected prompts to be closed")
+	}
+	if len(buffer.Contents) != len("hi!")+backgroundCallbacks {
+		t.Fatalf("unexpected buffer contents (%s)", buffer.Contents)
+	}
+}
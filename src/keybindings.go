@@ -53,6 +53,15 @@ func readKeybindings() {
 	}
 }
 
+func GetCommandKeybinding(cmd string) string {
+	for _, keybinding := range Keybindings.Keybindings {
+		if keybinding.Command == cmd {
+			return keybinding.Keybinding
+		}
+	}
+	return ""
+}
+
 func (keybinding *Keybinding) GetCursorModes() []CursorMode {
 	ret := make([]CursorMode, 0)
 
@@ -73,6 +82,11 @@ func (keybinding *Keybinding) IsPressed(ev *tcell.EventKey) bool {
 	if len(keys) == 0 {
 		return false
 	} else if len(keys) == 1 {
+		// Single characters, such as "n"
+		if ev.Key() == tcell.KeyRune {
+			return ev.Modifiers()&(tcell.ModAlt|tcell.ModCtrl|tcell.ModMeta) == 0 && keybinding.Keybinding == string(ev.Rune())
+		}
+
 		for k, v := range tcell.KeyNames {
 			if k != tcell.KeyRune {
 				if keybinding.Keybinding == v {
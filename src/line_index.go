@@ -1,26 +1,54 @@
 package main
 
 import (
-	"github.com/gdamore/tcell/v2"
 	"strconv"
-	"strings"
 )
 
+// Line numbering modes. Relative numbers count lines from the cursor, hybrid shows the absolute number on the cursor line
+var lineNumberModes = []string{"absolute", "relative", "hybrid"}
+
 func drawLineIndex(window *Window) {
 	screen := window.screen
 	buffer := window.CurrentBuffer
 
-	lineIndexStyle := tcell.StyleDefault.Background(CurrentStyle.LineIndexBg).Foreground(CurrentStyle.LineIndexFg)
+	lineIndexStyle := CurrentStyle.GetStyle("line_index")
+	currentLineStyle := CurrentStyle.GetStyle("line_index_current")
+
+	// Get lines with cursors on them
+	cursorLines := make(map[int]bool)
+	if window.HighlightCurrentLine {
+		for _, cursor := range buffer.GetCursors() {
+			_, line := window.CursorPosToCursorPos2D(cursor.Pos)
+			cursorLines[line] = true
+		}
+	}
+	_, cursorLine := window.GetCursorPos2D()
+
+	lineCount := buffer.GetLineCount()
+
+	var foldRanges map[int]int
+	if Config.ShowFoldMarkers {
+		foldRanges = buffer.GetFoldRanges()
+	}
 
 	lineIndexSize := getLineIndexSize(window)
 
-	_, bufferY1, _, bufferY2 := window.GetTextAreaDimensions()
+	bufferX1, bufferY1, _, bufferY2 := window.GetTextAreaDimensions()
+	lineIndexX := bufferX1 - lineIndexSize
+	numbersX2 := bufferX1 - getFoldColumnWidth()
+
+	// Get wrapped and folded rows
+	var rows []VisualRow
+	if window.UsesVisualRows() {
+		rows = window.GetVisualRows()
+	}
 
 	lineIndex := 1 + buffer.OffsetY
 	for y := bufferY1; y <= bufferY2; y++ {
-		if lineIndex > strings.Count(buffer.Contents, "\n")+1 {
+		row := buffer.OffsetY + y - bufferY1
+		if (rows == nil && lineIndex > lineCount) || (rows != nil && row >= len(rows)) {
 			if Config.ExtendLineIndex {
-				for x := 0; x < lineIndexSize; x++ {
+				for x := lineIndexX; x < bufferX1; x++ {
 					screen.SetContent(x, y, ' ', nil, lineIndexStyle)
 				}
 				continue
@@ -29,20 +57,67 @@ func drawLineIndex(window *Window) {
 			}
 		}
 
-		for x := 0; x < lineIndexSize; x++ {
+		for x := lineIndexX; x < bufferX1; x++ {
 			screen.SetContent(x, y, ' ', nil, lineIndexStyle)
 		}
 
-		text := strconv.Itoa(lineIndex)
+		// Only number the first row of wrapped lines
+		if rows != nil {
+			if !rows[row].First {
+				continue
+			}
+			lineIndex = rows[row].Line + 1
+		}
+
+		style := lineIndexStyle
+		if cursorLines[lineIndex-1] {
+			style = currentLineStyle
+			for x := lineIndexX; x < bufferX1; x++ {
+				screen.SetContent(x, y, ' ', nil, style)
+			}
+		}
+
+		if Config.ShowSigns {
+			if sign, ok := buffer.GetSign(lineIndex - 1); ok {
+				screen.SetContent(lineIndexX, y, sign.Char, nil, style.Foreground(CurrentStyle.GetColor(sign.Color)))
+			}
+		}
+
+		text := getLineNumber(window.LineNumbers, lineIndex-1, cursorLine)
+
+		drawText(screen, numbersX2-len(text)-1, y, numbersX2, y, style, text)
 
-		drawText(screen, lineIndexSize-len(text)-1, y, lineIndexSize, y, lineIndexStyle, text)
+		if _, ok := foldRanges[lineIndex-1]; ok {
+			marker := '▾'
+			if buffer.IsFolded(lineIndex - 1) {
+				marker = '▸'
+			}
+			screen.SetContent(numbersX2, y, marker, nil, style.Foreground(CurrentStyle.FoldMarkerFg))
+		}
 
 		lineIndex++
 	}
 }
 
+// getLineNumber returns the number shown next to a line in the given numbering mode
+func getLineNumber(mode string, line, cursorLine int) string {
+	if mode == "absolute" || (mode == "hybrid" && line == cursorLine) {
+		return strconv.Itoa(line + 1)
+	}
+
+	return strconv.Itoa(max(line-cursorLine, cursorLine-line))
+}
+
+// getFoldColumnWidth returns the width of the fold markers and the space after them
+func getFoldColumnWidth() int {
+	if Config.ShowFoldMarkers {
+		return 2
+	}
+	return 0
+}
+
 func getLineIndexSize(window *Window) int {
-	i := strings.Count(window.CurrentBuffer.Contents, "\n") + 1
+	i := window.CurrentBuffer.GetLineCount()
 	if i == 0 {
 		return 4
 	}
@@ -58,5 +133,11 @@ func getLineIndexSize(window *Window) int {
 		count += 1
 	}
 
+	// Leave space for signs and fold markers
+	if Config.ShowSigns {
+		count++
+	}
+	count += getFoldColumnWidth()
+
 	return count
 }
@@ -14,12 +14,24 @@ func main() {
 	// Read key bindings
 	readKeybindings()
 
+	// Read top menu
+	readMenu()
+
 	// Read styles
 	readStyles()
 
 	// Initialize commands
 	initCommands()
 
+	// Initialize input completion
+	initInputCompleters()
+
+	// Read input history
+	readInputHistory()
+
+	// Initialize file browser
+	initFileBrowser()
+
 	window, err := CreateWindow()
 	if err != nil {
 		log.Fatalf("Failed to create window: %v", err)
@@ -29,7 +41,7 @@ func main() {
 		for i, file := range os.Args[1:] {
 			b, err := CreateFileBuffer(file, true)
 			if err != nil {
-				PrintMessage(window, "Could not open file: "+file)
+				PrintError(window, "Could not open file: "+file)
 				continue
 			}
 
@@ -47,4 +59,9 @@ func main() {
 
 	window.screen.Fini()
 	window.screen = nil
+
+	// Write input history
+	if err := writeInputHistory(); err != nil {
+		log.Printf("Could not write input history: %s", err)
+	}
 }
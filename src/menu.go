@@ -0,0 +1,209 @@
+package main
+
+import (
+	"gopkg.in/yaml.v3"
+	"log"
+	"os"
+	"path"
+)
+
+type TyperMenu struct {
+	Menus []Menu `yaml:"menus"`
+}
+
+type Menu struct {
+	Name  string     `yaml:"name"`
+	Items []MenuItem `yaml:"items"`
+}
+
+type MenuItem struct {
+	Label     string     `yaml:"label,omitempty"`
+	Command   string     `yaml:"command,omitempty"`
+	Args      []string   `yaml:"args,omitempty"`
+	Separator bool       `yaml:"separator,omitempty"`
+	Items     []MenuItem `yaml:"items,omitempty"`
+
+	// Name of a list of generated items, such as "buffers"
+	Generate string `yaml:"generate,omitempty"`
+}
+
+var TopMenu TyperMenu
+
+// menuItemGenerators return items generated when a menu is opened
+var menuItemGenerators = map[string]func(window *Window) []DropdownItem{
+	"buffers": getBufferDropdownItems,
+}
+
+// menuDisabledStates return whether the items running a command are disabled
+var menuDisabledStates = map[string]func(window *Window) bool{
+	"cut":         func(window *Window) bool { return window.CurrentBuffer.readOnly },
+	"paste":       func(window *Window) bool { return window.CurrentBuffer.readOnly },
+	"replace":     func(window *Window) bool { return window.CurrentBuffer.readOnly },
+	"replace-all": func(window *Window) bool { return window.CurrentBuffer.readOnly },
+	"save":        func(window *Window) bool { return !window.CurrentBuffer.canSave },
+	"reload":      func(window *Window) bool { return window.CurrentBuffer.filename == "" },
+	"grep-cancel": func(window *Window) bool { return currentGrepSearch == nil },
+}
+
+var defaultTopMenu = TyperMenu{
+	Menus: []Menu{
+		{Name: "File", Items: []MenuItem{
+			{Label: "New", Command: "new-buffer"},
+			{Label: "Open", Command: "open"},
+			{Label: "Find File", Command: "find-file"},
+			{Separator: true},
+			{Label: "Save", Command: "save"},
+			{Label: "Reload", Command: "reload"},
+			{Separator: true},
+			{Label: "File Browser", Items: []MenuItem{
+				{Label: "Focus", Command: "focus-file-browser"},
+				{Label: "New File", Command: "file-browser-new"},
+				{Label: "Rename", Command: "file-browser-rename"},
+				{Label: "Delete", Command: "file-browser-delete"},
+				{Label: "Refresh", Command: "file-browser-refresh"},
+			}},
+			{Separator: true},
+			{Label: "Close", Command: "close-buffer"},
+			{Label: "Quit", Command: "quit"},
+		}},
+		{Name: "Edit", Items: []MenuItem{
+			{Label: "Cut", Command: "cut"},
+			{Label: "Copy", Command: "copy"},
+			{Label: "Paste", Command: "paste"},
+			{Separator: true},
+			{Label: "Selection", Items: []MenuItem{
+				{Label: "Select All", Command: "select-all"},
+				{Label: "Select Word", Command: "select-word"},
+				{Label: "Select Line", Command: "select-line"},
+				{Label: "Select Paragraph", Command: "select-paragraph"},
+				{Label: "Expand Selection", Command: "expand-selection"},
+			}},
+			{Label: "Cursors", Items: []MenuItem{
+				{Label: "Add Cursor Above", Command: "add-cursor-above"},
+				{Label: "Add Cursor Below", Command: "add-cursor-below"},
+				{Label: "Add Cursor at Next Match", Command: "add-cursor-next-match"},
+				{Label: "Add Cursors at Matches", Command: "add-cursors-at-matches"},
+			}},
+			{Separator: true},
+			{Label: "Go to Matching Bracket", Command: "goto-matching-bracket"},
+			{Label: "Folding", Items: []MenuItem{
+				{Label: "Fold", Command: "fold"},
+				{Label: "Unfold", Command: "unfold"},
+				{Label: "Toggle Fold", Command: "toggle-fold"},
+				{Label: "Fold All", Command: "fold-all"},
+				{Label: "Unfold All", Command: "unfold-all"},
+			}},
+		}},
+		{Name: "Search", Items: []MenuItem{
+			{Label: "Find", Command: "find"},
+			{Label: "Replace", Command: "replace"},
+			{Label: "Replace All", Command: "replace-all"},
+			{Separator: true},
+			{Label: "Search in Project", Command: "grep"},
+			{Label: "Replace in Project", Command: "grep-replace"},
+			{Label: "Cancel Project Search", Command: "grep-cancel"},
+		}},
+		{Name: "View", Items: []MenuItem{
+			{Label: "Top Menu", Command: "toggle-top-bar"},
+			{Label: "Tab Bar", Command: "toggle-tab-bar"},
+			{Label: "Status Line", Command: "toggle-status-line"},
+			{Label: "Line Index", Command: "toggle-line-index"},
+			{Label: "Line Numbers", Command: "cycle-line-numbers"},
+			{Label: "Scrollbar", Command: "toggle-scrollbar"},
+			{Label: "Minimap", Command: "toggle-minimap"},
+			{Label: "File Browser", Command: "toggle-file-browser"},
+			{Separator: true},
+			{Label: "Soft Wrap", Command: "toggle-wrap"},
+			{Label: "Current Line", Command: "toggle-current-line"},
+			{Label: "Whitespace", Command: "toggle-whitespace"},
+			{Label: "Rulers", Command: "toggle-rulers"},
+			{Separator: true},
+			{Label: "Style", Items: []MenuItem{
+				{Label: "Default", Command: "set-style", Args: []string{"default"}},
+				{Label: "Classic", Command: "set-style", Args: []string{"classic"}},
+				{Label: "Choose Style", Command: "set-style"},
+			}},
+		}},
+		{Name: "Buffers", Items: []MenuItem{
+			{Label: "Previous Buffer", Command: "prev-buffer"},
+			{Label: "Next Buffer", Command: "next-buffer"},
+			{Label: "Move Left", Command: "move-buffer-left"},
+			{Label: "Move Right", Command: "move-buffer-right"},
+			{Separator: true},
+			{Generate: "buffers"},
+		}},
+		{Name: "Help", Items: []MenuItem{
+			{Label: "Help", Command: "help"},
+			{Label: "Describe Key", Command: "describe-key"},
+			{Separator: true},
+			{Label: "Command Palette", Command: "execute"},
+			{Label: "Show Messages", Command: "show-messages"},
+			{Label: "Dismiss Messages", Command: "dismiss-messages"},
+		}},
+	},
+}
+
+func readMenu() {
+	TopMenu = defaultTopMenu
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		log.Fatalf("Could not get home directory: %s", err)
+	}
+
+	if _, err := os.Stat(path.Join(homeDir, ".config/typer/menu.yml")); err == nil {
+		data, err := os.ReadFile(path.Join(homeDir, ".config/typer/menu.yml"))
+		if err != nil {
+			log.Fatalf("Could not read menu.yml: %s", err)
+		}
+		TopMenu = TyperMenu{}
+		err = yaml.Unmarshal(data, &TopMenu)
+		if err != nil {
+			log.Fatalf("Could not unmarshal menu.yml: %s", err)
+		}
+	} else if _, err := os.Stat(path.Join(sysconfdir, "typer/menu.yml")); err == nil {
+		reader, err := os.Open(path.Join(sysconfdir, "typer/menu.yml"))
+		if err != nil {
+			log.Fatalf("Could not read menu.yml: %s", err)
+		}
+		TopMenu = TyperMenu{}
+		err = yaml.NewDecoder(reader).Decode(&TopMenu)
+		if err != nil {
+			log.Fatalf("Could not read menu.yml: %s", err)
+		}
+		reader.Close()
+	}
+}
+
+// GetDropdownItems converts menu items to dropdown items, generating items and disabling commands that cannot run
+func GetDropdownItems(window *Window, menuItems []MenuItem) []DropdownItem {
+	items := make([]DropdownItem, 0, len(menuItems))
+
+	for _, menuItem := range menuItems {
+		if menuItem.Generate != "" {
+			if generator, ok := menuItemGenerators[menuItem.Generate]; ok {
+				items = append(items, generator(window)...)
+			}
+			continue
+		}
+
+		item := DropdownItem{
+			Label:     menuItem.Label,
+			Command:   menuItem.Command,
+			Args:      menuItem.Args,
+			Separator: menuItem.Separator,
+		}
+
+		if disabled, ok := menuDisabledStates[menuItem.Command]; ok {
+			item.Disabled = disabled(window)
+		}
+
+		if menuItem.Items != nil {
+			item.Submenu = GetDropdownItems(window, menuItem.Items)
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
@@ -1,19 +1,62 @@
 package main
 
 import (
+	"fmt"
 	"github.com/gdamore/tcell/v2"
+	"strings"
 	"time"
 )
 
+type MessageLevel uint8
+
+const (
+	MessageLevelInfo MessageLevel = iota
+	MessageLevelWarning
+	MessageLevelError
+)
+
+var MessageLevelNames = map[MessageLevel]string{
+	MessageLevelInfo:    "info",
+	MessageLevelWarning: "warning",
+	MessageLevelError:   "error",
+}
+
 type TyperMessage struct {
 	timestamp int64
 	message   string
+	level     MessageLevel
+	dismissed bool
 }
 
+const messagesBufferName = "Messages"
+
 var messageLog = make([]TyperMessage, 0)
 
+// Indices of the first and the latest error in the message log that were not dismissed, or -1 if there are none.
+// Dismissing hides all errors at once, so every error between them is still shown
+var firstErrorIndex, latestErrorIndex = -1, -1
+
 func PrintMessage(window *Window, message string) {
-	messageLog = append(messageLog, TyperMessage{timestamp: time.Now().UnixMilli(), message: message})
+	printMessageWithLevel(window, message, MessageLevelInfo)
+}
+
+func PrintWarning(window *Window, message string) {
+	printMessageWithLevel(window, message, MessageLevelWarning)
+}
+
+// PrintError shows an error message that stays visible until it is dismissed
+func PrintError(window *Window, message string) {
+	printMessageWithLevel(window, message, MessageLevelError)
+}
+
+func printMessageWithLevel(window *Window, message string, level MessageLevel) {
+	messageLog = append(messageLog, TyperMessage{timestamp: time.Now().UnixMilli(), message: message, level: level})
+	if level == MessageLevelError {
+		latestErrorIndex = len(messageLog) - 1
+		if firstErrorIndex == -1 {
+			firstErrorIndex = latestErrorIndex
+		}
+	}
 
 	err := window.screen.PostEvent(tcell.NewEventInterrupt(nil))
 	if err != nil {
@@ -30,22 +73,107 @@ func PrintMessage(window *Window, message string) {
 	}()
 }
 
+// DismissMessages hides error messages that are still shown. It returns false if there was nothing to dismiss
+func DismissMessages() bool {
+	if latestErrorIndex == -1 {
+		return false
+	}
+
+	for i := firstErrorIndex; i <= latestErrorIndex; i++ {
+		if messageLog[i].level == MessageLevelError {
+			messageLog[i].dismissed = true
+		}
+	}
+
+	firstErrorIndex, latestErrorIndex = -1, -1
+	return true
+}
+
+// getCurrentMessage returns the message shown in the message bar, if any.
+// The newest message is shown for a few seconds, after which the most recent error that was not dismissed is shown again
+func getCurrentMessage() *TyperMessage {
+	if len(messageLog) == 0 {
+		return nil
+	}
+
+	message := &messageLog[len(messageLog)-1]
+	if time.Since(time.UnixMilli(message.timestamp)).Seconds() < 5 {
+		return message
+	}
+
+	if latestErrorIndex != -1 {
+		return &messageLog[latestErrorIndex]
+	}
+
+	return nil
+}
+
+// ShowMessageLog opens all messages with their timestamps in a read-only buffer
+func ShowMessageLog(window *Window) {
+	buffer := GetBufferByName(messagesBufferName)
+	if buffer == nil {
+		var err error
+		buffer, err = CreateBuffer(messagesBufferName)
+		if err != nil {
+			PrintError(window, fmt.Sprintf("Could not create messages buffer: %s", err))
+			return
+		}
+	}
+	buffer.canSave = false
+	buffer.readOnly = true
+
+	builder := strings.Builder{}
+	for _, message := range messageLog {
+		timestamp := time.UnixMilli(message.timestamp).Format("2006-01-02 15:04:05")
+		builder.WriteString(fmt.Sprintf("%s [%s] %s\n", timestamp, strings.ToUpper(MessageLevelNames[message.level]), message.message))
+	}
+	buffer.SetContents(builder.String())
+
+	buffer.Selection = nil
+	buffer.CollapseCursors()
+	buffer.BlockSelection = nil
+
+	window.CurrentBuffer = buffer
+	window.CursorMode = CursorModeBuffer
+
+	// Show latest messages
+	window.SetCursorPos(len(buffer.Contents))
+}
+
 func drawMessageBar(window *Window) {
 	screen := window.screen
 
-	messageBarStyle := tcell.StyleDefault.Background(CurrentStyle.MessageBarBg).Foreground(CurrentStyle.MessageBarFg)
+	messageBarStyle := CurrentStyle.GetStyle("message_bar")
 
 	sizeX, sizeY := screen.Size()
 
 	messageToPrint := ""
-	if len(messageLog) > 0 && time.Since(time.UnixMilli(messageLog[len(messageLog)-1].timestamp)).Seconds() < 5 {
-		messageToPrint = messageLog[len(messageLog)-1].message
+	if message := getCurrentMessage(); message != nil {
+		messageToPrint = strings.Join(strings.Fields(message.message), " ")
+
+		switch message.level {
+		case MessageLevelWarning:
+			messageBarStyle = CurrentStyle.GetStyle("message_bar_warning")
+		case MessageLevelError:
+			messageBarStyle = CurrentStyle.GetStyle("message_bar_error")
+		}
+	}
+
+	// Keep status line visible above the input bar if there is no message
+	if currentInputRequest != nil && messageToPrint == "" && window.ShowStatusLine {
+		return
+	}
+
+	// Truncate long messages
+	message := []rune(messageToPrint)
+	if len(message) > sizeX {
+		message = append(message[:max(sizeX-1, 0)], '…')
 	}
 
 	for x := 0; x < sizeX; x++ {
 		char := ' '
-		if x < len(messageToPrint) {
-			char = int32(messageToPrint[x])
+		if x < len(message) {
+			char = message[x]
 		}
 
 		if currentInputRequest == nil {
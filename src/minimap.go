@@ -0,0 +1,136 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Each minimap cell shows two columns and four lines of the buffer as braille dots
+const minimapLinesPerRow = 4
+const minimapColumnsPerCell = 2
+
+var minimapDragging = false
+
+// Braille dot bits indexed by line and column within a cell
+var minimapDots = [minimapLinesPerRow][minimapColumnsPerCell]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+func getMinimapWidth(window *Window) int {
+	sizeX, _ := window.screen.Size()
+	return min(Config.MinimapWidth, sizeX/4)
+}
+
+func getMinimapDimensions(window *Window) (int, int, int, int) {
+	_, y1, x2, y2 := window.GetTextAreaDimensions()
+
+	return x2 + 1, y1, x2 + getMinimapWidth(window), y2
+}
+
+// getMinimapView returns the first and last line visible in the text area
+func (window *Window) getMinimapView() (int, int) {
+	buffer := window.CurrentBuffer
+	_, y1, _, y2 := window.GetTextAreaDimensions()
+	lineCount := buffer.GetLineCount()
+
+	if !window.UsesVisualRows() {
+		return buffer.OffsetY, min(buffer.OffsetY+y2-y1, lineCount-1)
+	}
+
+	rows := window.GetVisualRows()
+	first := rows[min(buffer.OffsetY, len(rows)-1)].Line
+	last := rows[min(buffer.OffsetY+y2-y1, len(rows)-1)].Line
+	return first, last
+}
+
+// getMinimapStartRow returns the first minimap row shown. The minimap scrolls along with the buffer if it does not fit
+func (window *Window) getMinimapStartRow() int {
+	_, y1, _, y2 := getMinimapDimensions(window)
+	height := y2 - y1 + 1
+
+	lineCount := window.CurrentBuffer.GetLineCount()
+	mapRows := (lineCount + minimapLinesPerRow - 1) / minimapLinesPerRow
+	if mapRows <= height {
+		return 0
+	}
+
+	first, last := window.getMinimapView()
+	return min(first*(mapRows-height)/max(lineCount-(last-first+1), 1), mapRows-height)
+}
+
+// handleMinimapMouse scrolls the buffer so the clicked line is centered in the text area
+func handleMinimapMouse(window *Window, mouseX, mouseY int) bool {
+	x1, y1, x2, y2 := getMinimapDimensions(window)
+
+	if !minimapDragging {
+		if mouseHeld || !window.ShowMinimap || mouseX < x1 || mouseX > x2 || mouseY < y1 || mouseY > y2 {
+			return false
+		}
+		minimapDragging = true
+	}
+
+	total, lineRows := window.getScrollRows()
+	row := min(max(mouseY, y1), y2) - y1 + window.getMinimapStartRow()
+	line := min(row*minimapLinesPerRow, len(lineRows)-1)
+
+	window.CurrentBuffer.OffsetY = min(max(lineRows[line]-(y2-y1)/2, 0), max(total-1, 0))
+
+	return true
+}
+
+func drawMinimap(window *Window) {
+	screen := window.screen
+
+	minimapStyle := CurrentStyle.GetStyle("minimap")
+	viewStyle := highlightStyle(minimapStyle, CurrentStyle.MinimapView)
+
+	x1, y1, x2, y2 := getMinimapDimensions(window)
+	width := x2 - x1 + 1
+	if width <= 0 || y2 < y1 {
+		return
+	}
+
+	lines := strings.Split(window.CurrentBuffer.Contents, "\n")
+	startRow := window.getMinimapStartRow()
+	viewFirst, viewLast := window.getMinimapView()
+
+	for y := y1; y <= y2; y++ {
+		firstLine := (startRow + y - y1) * minimapLinesPerRow
+
+		// Get braille dots of each cell in row
+		cells := make([]rune, width)
+		for i := 0; i < minimapLinesPerRow && firstLine+i < len(lines); i++ {
+			col := 0
+			for _, r := range lines[firstLine+i] {
+				if col >= width*minimapColumnsPerCell {
+					break
+				}
+
+				if r == '\t' {
+					col += Config.TabIndentation
+					continue
+				} else if !unicode.IsSpace(r) {
+					cells[col/minimapColumnsPerCell] |= minimapDots[i][col%minimapColumnsPerCell]
+				}
+				col++
+			}
+		}
+
+		// Highlight lines visible in the text area
+		style := minimapStyle
+		if firstLine < len(lines) && firstLine <= viewLast && firstLine+minimapLinesPerRow-1 >= viewFirst {
+			style = viewStyle
+		}
+
+		for i, dots := range cells {
+			char := ' '
+			if dots != 0 {
+				char = 0x2800 + dots
+			}
+			screen.SetContent(x1+i, y, char, nil, style)
+		}
+	}
+}
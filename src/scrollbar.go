@@ -0,0 +1,274 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"slices"
+	"strings"
+)
+
+// Substring of the last search, marked on the scrollbar
+var lastSearch = ""
+
+var scrollbarDragging = false
+
+// getScrollRows returns the number of rows the current buffer can be scrolled through and the first row of every line
+func (window *Window) getScrollRows() (int, []int) {
+	buffer := window.CurrentBuffer
+
+	lineRows := make([]int, buffer.GetLineCount())
+	if !window.UsesVisualRows() {
+		for i := range lineRows {
+			lineRows[i] = i
+		}
+		return len(lineRows), lineRows
+	}
+
+	rows := window.GetVisualRows()
+	for i := len(rows) - 1; i >= 0; i-- {
+		lineRows[rows[i].Line] = i
+	}
+
+	// Lines hidden by folds belong to the row of their fold
+	hiddenLines := buffer.GetHiddenLines()
+	for line := 1; line < len(lineRows) && line < len(hiddenLines); line++ {
+		if hiddenLines[line] {
+			lineRows[line] = lineRows[line-1]
+		}
+	}
+	return len(rows), lineRows
+}
+
+// Maximum number of inserted and removed lines diffed line by line. Larger changes mark every line between the first and last change
+const maxModifiedLinesEdits = 1000
+
+// GetModifiedLines returns which lines differ from the saved contents, including lines next to removed lines.
+// It is cached until the contents change or the buffer is loaded or saved
+func (buffer *Buffer) GetModifiedLines() []bool {
+	if buffer.modifiedLines != nil && buffer.modifiedLinesRevision == buffer.revision {
+		return buffer.modifiedLines
+	}
+
+	lines := strings.Split(buffer.Contents, "\n")
+	savedLines := strings.Split(buffer.savedContents, "\n")
+
+	modified := make([]bool, len(lines))
+	if buffer.canSave && !buffer.readOnly {
+		prefix := 0
+		for prefix < len(lines) && prefix < len(savedLines) && lines[prefix] == savedLines[prefix] {
+			prefix++
+		}
+
+		suffix := 0
+		for suffix < len(lines)-prefix && suffix < len(savedLines)-prefix && lines[len(lines)-1-suffix] == savedLines[len(savedLines)-1-suffix] {
+			suffix++
+		}
+
+		if !markModifiedLines(modified, prefix, savedLines[prefix:len(savedLines)-suffix], lines[prefix:len(lines)-suffix]) {
+			for i := prefix; i < len(lines)-suffix; i++ {
+				modified[i] = true
+			}
+			if prefix+suffix == len(lines) && len(lines) != len(savedLines) {
+				modified[min(prefix, len(lines)-1)] = true
+			}
+		}
+	}
+
+	buffer.modifiedLines = modified
+	buffer.modifiedLinesRevision = buffer.revision
+	buffer.setModifiedSigns(modified)
+
+	return modified
+}
+
+// markModifiedLines diffs the lines old and new, which start at line offset, and marks inserted lines and lines next to
+// removed lines in modified. It returns false without marking anything if more than maxModifiedLinesEdits edits are needed
+func markModifiedLines(modified []bool, offset int, old, new []string) bool {
+	n, m := len(old), len(new)
+	maxEdits := min(n+m, maxModifiedLinesEdits)
+
+	// Find a shortest edit script with Myers' algorithm. v holds the furthest x reached on every diagonal k = x - y
+	v := make([]int, 2*maxEdits+2)
+	trace := make([][]int, 0)
+	edits := -1
+	for d := 0; d <= maxEdits && edits == -1; d++ {
+		trace = append(trace, slices.Clone(v[maxEdits-d:maxEdits+d+2]))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[maxEdits+k-1] < v[maxEdits+k+1]) {
+				x = v[maxEdits+k+1]
+			} else {
+				x = v[maxEdits+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && old[x] == new[y] {
+				x++
+				y++
+			}
+			v[maxEdits+k] = x
+
+			if x >= n && y >= m {
+				edits = d
+				break
+			}
+		}
+	}
+	if edits == -1 {
+		return false
+	}
+
+	// Walk the edit script backwards. trace[d] holds the diagonals -d to d+1 reached after d-1 edits
+	x, y := n, m
+	for d := edits; d > 0; d-- {
+		prev := func(k int) int { return trace[d][k+d] }
+
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && prev(k-1) < prev(k+1)) {
+			prevK = k + 1
+		}
+		prevX := prev(prevK)
+		prevY := prevX - prevK
+
+		if prevK == k+1 {
+			// Line was inserted
+			modified[offset+prevY] = true
+		} else {
+			// Line was removed
+			modified[min(offset+prevY, len(modified)-1)] = true
+		}
+
+		x, y = prevX, prevY
+	}
+
+	return true
+}
+
+// getSearchMatchLines returns the lines containing the last searched substring.
+// It is cached until the contents or the search change
+func (buffer *Buffer) getSearchMatchLines() []int {
+	if buffer.searchLines != nil && buffer.searchLinesRevision == buffer.revision && buffer.searchLinesSearch == lastSearch {
+		return buffer.searchLines
+	}
+
+	lines := make([]int, 0)
+	if lastSearch != "" {
+		line, lineStart := 0, 0
+		for pos := 0; ; {
+			index := strings.Index(buffer.Contents[pos:], lastSearch)
+			if index == -1 {
+				break
+			}
+			pos += index
+
+			line += strings.Count(buffer.Contents[lineStart:pos], "\n")
+			lineStart = pos
+			if len(lines) == 0 || lines[len(lines)-1] != line {
+				lines = append(lines, line)
+			}
+
+			pos += len(lastSearch)
+		}
+	}
+
+	buffer.searchLines = lines
+	buffer.searchLinesRevision = buffer.revision
+	buffer.searchLinesSearch = lastSearch
+	buffer.setSearchSigns(lines)
+
+	return lines
+}
+
+func getScrollbarX(window *Window) int {
+	sizeX, _ := window.screen.Size()
+	return sizeX - 1
+}
+
+// getScrollbarThumb returns the first row and the height of the scrollbar thumb
+func getScrollbarThumb(offset, total, height int) (int, int) {
+	if total <= height {
+		return 0, height
+	}
+
+	size := max(height*height/total, 1)
+	start := min(offset*(height-size)/(total-height), height-size)
+
+	return start, size
+}
+
+// handleScrollbarMouse scrolls the buffer so the scrollbar thumb is centered on the mouse
+func handleScrollbarMouse(window *Window, mouseX, mouseY int) bool {
+	_, y1, _, y2 := window.GetTextAreaDimensions()
+
+	if !scrollbarDragging {
+		if mouseHeld || !window.ShowScrollbar || mouseX != getScrollbarX(window) || mouseY < y1 || mouseY > y2 {
+			return false
+		}
+		scrollbarDragging = true
+	}
+
+	height := y2 - y1 + 1
+	total, _ := window.getScrollRows()
+	_, size := getScrollbarThumb(window.CurrentBuffer.OffsetY, total, height)
+	if size >= height {
+		return true
+	}
+
+	start := min(max(mouseY-y1-size/2, 0), height-size)
+	window.CurrentBuffer.OffsetY = start * (total - height) / (height - size)
+
+	return true
+}
+
+func drawScrollbar(window *Window) {
+	screen := window.screen
+	buffer := window.CurrentBuffer
+
+	trackStyle := tcell.StyleDefault.Background(CurrentStyle.ScrollbarBg)
+	thumbStyle := highlightStyle(trackStyle, CurrentStyle.ScrollbarThumb)
+
+	_, y1, _, y2 := window.GetTextAreaDimensions()
+	x := getScrollbarX(window)
+	height := y2 - y1 + 1
+	if height <= 0 {
+		return
+	}
+
+	total, lineRows := window.getScrollRows()
+	thumbStart, thumbSize := getScrollbarThumb(buffer.OffsetY, total, height)
+
+	// Get markers for each row, later markers take precedence
+	markers := make([]tcell.Color, height)
+	markLine := func(line int, color tcell.Color) {
+		if line >= 0 && line < len(lineRows) {
+			markers[min(lineRows[line]*height/max(total, 1), height-1)] = color
+		}
+	}
+
+	for line, modified := range buffer.GetModifiedLines() {
+		if modified {
+			markLine(line, CurrentStyle.ScrollbarModified)
+		}
+	}
+	for _, line := range buffer.getSearchMatchLines() {
+		markLine(line, CurrentStyle.ScrollbarMatch)
+	}
+	for _, cursor := range buffer.GetCursors() {
+		_, line := window.CursorPosToCursorPos2D(cursor.Pos)
+		markLine(line, CurrentStyle.ScrollbarCursor)
+	}
+
+	for row := 0; row < height; row++ {
+		style := trackStyle
+		if row >= thumbStart && row < thumbStart+thumbSize {
+			style = thumbStyle
+		}
+
+		if markers[row] != tcell.ColorDefault {
+			screen.SetContent(x, y1+row, '▬', nil, style.Foreground(markers[row]))
+		} else {
+			screen.SetContent(x, y1+row, ' ', nil, style)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGetModifiedLinesHunks(t *testing.T) {
+	tests := []struct {
+		name          string
+		saved, edited string
+		expected      []int
+	}{
+		{"unchanged", "a\nb\nc\n", "a\nb\nc\n", []int{}},
+		{"separate changes", "a\nb\nc\nd\ne\n", "A\nb\nc\nd\nE\n", []int{0, 4}},
+		{"inserted line", "a\nb\nc\n", "a\nb\nx\nc\n", []int{2}},
+		{"removed line", "a\nb\nc\nd\n", "a\nc\nd\n", []int{1}},
+		{"removed last lines", "a\nb\nc", "a", []int{0}},
+		{"inserted and removed lines", "a\nb\nc\nd\ne\n", "a\nx\nc\nd\n", []int{1, 4}},
+	}
+
+	for _, test := range tests {
+		buffer := &Buffer{canSave: true, savedContents: test.saved}
+		buffer.SetContents(test.edited)
+
+		lines := make([]int, 0)
+		for line, modified := range buffer.GetModifiedLines() {
+			if modified {
+				lines = append(lines, line)
+			}
+		}
+
+		if !slices.Equal(lines, test.expected) {
+			t.Errorf("%s: expected modified lines %v, got %v", test.name, test.expected, lines)
+		}
+	}
+}
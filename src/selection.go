@@ -0,0 +1,187 @@
+package main
+
+import (
+	"strings"
+)
+
+// GetLineAt returns the first and last position of the line containing pos, including its new line character
+func (buffer *Buffer) GetLineAt(pos int) (int, int) {
+	pos = min(max(pos, 0), len(buffer.Contents))
+
+	start := strings.LastIndexByte(buffer.Contents[:pos], '\n') + 1
+	end := strings.IndexByte(buffer.Contents[pos:], '\n')
+	if end == -1 {
+		end = len(buffer.Contents) - 1
+	} else {
+		end += pos
+	}
+
+	return start, end
+}
+
+// GetParagraphAt returns the first and last position of the block of non-empty lines containing pos
+func (buffer *Buffer) GetParagraphAt(pos int) (int, int) {
+	start, end := buffer.GetLineAt(pos)
+
+	isBlank := func(start, end int) bool {
+		return strings.TrimSpace(buffer.Contents[start:min(end+1, len(buffer.Contents))]) == ""
+	}
+
+	if isBlank(start, end) {
+		return start, end
+	}
+
+	// Find first line of paragraph
+	for start > 0 {
+		prevStart, prevEnd := buffer.GetLineAt(start - 1)
+		if isBlank(prevStart, prevEnd) {
+			break
+		}
+		start = prevStart
+	}
+
+	// Find last line of paragraph
+	for end+1 < len(buffer.Contents) {
+		nextStart, nextEnd := buffer.GetLineAt(end + 1)
+		if isBlank(nextStart, nextEnd) {
+			break
+		}
+		end = nextEnd
+	}
+
+	return start, end
+}
+
+// getEnclosingBrackets returns the positions of the innermost bracket pair surrounding the range between start and end
+func (buffer *Buffer) getEnclosingBrackets(start, end int) (int, int) {
+	depth := make(map[byte]int)
+	for i := start - 1; i >= 0; i-- {
+		c := buffer.Contents[i]
+
+		for openChar, closeChar := range bracketPairs {
+			if c == closeChar {
+				depth[openChar]++
+			} else if c == openChar && depth[openChar] > 0 {
+				depth[openChar]--
+			} else if c == openChar {
+				if match := buffer.FindMatchingBracket(i); match > end {
+					return i, match
+				}
+			}
+		}
+	}
+
+	return -1, -1
+}
+
+// getEnclosingQuotes returns the positions of the closest pair of quotes on the same line surrounding the range between start and end
+func (buffer *Buffer) getEnclosingQuotes(start, end int) (int, int) {
+	lineStart, lineEnd := buffer.GetLineAt(start)
+	if end > lineEnd {
+		return -1, -1
+	}
+
+	bestOpen, bestClose := -1, -1
+	for _, quote := range quoteChars {
+		openPos := strings.LastIndexByte(buffer.Contents[lineStart:start], quote)
+		if openPos == -1 || end+1 > lineEnd {
+			continue
+		}
+
+		closePos := strings.IndexByte(buffer.Contents[end+1:lineEnd+1], quote)
+		if closePos == -1 {
+			continue
+		}
+
+		openPos += lineStart
+		closePos += end + 1
+		if bestOpen == -1 || closePos-openPos < bestClose-bestOpen {
+			bestOpen, bestClose = openPos, closePos
+		}
+	}
+
+	return bestOpen, bestClose
+}
+
+func (window *Window) selectRange(start, end int) {
+	buffer := window.CurrentBuffer
+
+	// Prevent selecting dummy character at the end of the buffer
+	end = min(end, len(buffer.Contents)-1)
+	if end < start {
+		return
+	}
+
+	buffer.Selection = &Selection{
+		selectionStart: start,
+		selectionEnd:   end,
+	}
+	window.SetCursorPos(end)
+}
+
+func (window *Window) SelectAll() {
+	window.selectRange(0, len(window.CurrentBuffer.Contents)-1)
+}
+
+func (window *Window) SelectWord() {
+	window.selectRange(window.CurrentBuffer.GetWordAt(window.CurrentBuffer.CursorPos))
+}
+
+func (window *Window) SelectLine() {
+	window.selectRange(window.CurrentBuffer.GetLineAt(window.CurrentBuffer.CursorPos))
+}
+
+func (window *Window) SelectParagraph() {
+	window.selectRange(window.CurrentBuffer.GetParagraphAt(window.CurrentBuffer.CursorPos))
+}
+
+// ExpandSelection grows the selection to the word, then to enclosing quotes or brackets, then to the line and then to the block
+func (window *Window) ExpandSelection() {
+	buffer := window.CurrentBuffer
+
+	if len(buffer.Contents) == 0 {
+		return
+	}
+
+	start, end := buffer.CursorPos, buffer.CursorPos-1
+	if buffer.Selection != nil {
+		start, end = buffer.GetSelectionEdges()
+	}
+
+	candidates := make([][2]int, 0)
+
+	wordStart, wordEnd := buffer.GetWordAt(start)
+	candidates = append(candidates, [2]int{wordStart, wordEnd})
+
+	if openPos, closePos := buffer.getEnclosingQuotes(start, end); openPos != -1 {
+		candidates = append(candidates, [2]int{openPos + 1, closePos - 1}, [2]int{openPos, closePos})
+	}
+
+	if openPos, closePos := buffer.getEnclosingBrackets(start, end); openPos != -1 {
+		candidates = append(candidates, [2]int{openPos + 1, closePos - 1}, [2]int{openPos, closePos})
+	}
+
+	lineStart, lineEnd := buffer.GetLineAt(start)
+	candidates = append(candidates, [2]int{lineStart, lineEnd})
+
+	paragraphStart, paragraphEnd := buffer.GetParagraphAt(start)
+	candidates = append(candidates, [2]int{paragraphStart, paragraphEnd})
+
+	candidates = append(candidates, [2]int{0, len(buffer.Contents) - 1})
+
+	// Select the smallest candidate that is larger than the current selection
+	best := -1
+	for i, candidate := range candidates {
+		if candidate[0] > start || candidate[1] < end || candidate[1]-candidate[0] <= end-start || candidate[1] < candidate[0] {
+			continue
+		}
+
+		if best == -1 || candidate[1]-candidate[0] < candidates[best][1]-candidates[best][0] {
+			best = i
+		}
+	}
+
+	if best != -1 {
+		window.selectRange(candidates[best][0], candidates[best][1])
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"slices"
+)
+
+// Sign is a character shown in the line index next to a line, such as a diagnostic or a bookmark
+type Sign struct {
+	Char rune
+
+	// Name of the style color of the sign, such as "sign_modified_fg"
+	Color string
+
+	// Signs with higher priority are shown when a line has several signs
+	Priority int
+}
+
+// SetSign shows a sign next to a line. Signs are grouped by the subsystem setting them, such as "git" or "bookmarks"
+func (buffer *Buffer) SetSign(group string, line int, sign Sign) {
+	if buffer.signs == nil {
+		buffer.signs = make(map[string]map[int]Sign)
+	}
+	if buffer.signs[group] == nil {
+		buffer.signs[group] = make(map[int]Sign)
+
+		// Keep groups sorted, so signs of equal priority are picked the same way every time
+		i, _ := slices.BinarySearch(buffer.signGroups, group)
+		buffer.signGroups = slices.Insert(buffer.signGroups, i, group)
+	}
+
+	buffer.signs[group][line] = sign
+}
+
+func (buffer *Buffer) RemoveSign(group string, line int) {
+	delete(buffer.signs[group], line)
+}
+
+// ClearSigns removes all signs of a group
+func (buffer *Buffer) ClearSigns(group string) {
+	delete(buffer.signs, group)
+
+	if i, ok := slices.BinarySearch(buffer.signGroups, group); ok {
+		buffer.signGroups = slices.Delete(buffer.signGroups, i, i+1)
+	}
+}
+
+// GetSign returns the sign with the highest priority on a line
+func (buffer *Buffer) GetSign(line int) (Sign, bool) {
+	var result Sign
+	found := false
+	for _, group := range buffer.signGroups {
+		if sign, ok := buffer.signs[group][line]; ok && (!found || sign.Priority > result.Priority) {
+			result = sign
+			found = true
+		}
+	}
+
+	return result, found
+}
+
+// updateSigns brings the signs of modified lines and search matches up to date.
+// They are only set again when the modified lines or search matches are recomputed, after the contents or the search change
+func (buffer *Buffer) updateSigns() {
+	buffer.GetModifiedLines()
+	buffer.getSearchMatchLines()
+}
+
+func (buffer *Buffer) setModifiedSigns(modifiedLines []bool) {
+	buffer.ClearSigns("modified")
+	for line, modified := range modifiedLines {
+		if modified {
+			buffer.SetSign("modified", line, Sign{Char: '▎', Color: "sign_modified_fg", Priority: 10})
+		}
+	}
+}
+
+func (buffer *Buffer) setSearchSigns(lines []int) {
+	buffer.ClearSigns("search")
+	for _, line := range lines {
+		buffer.SetSign("search", line, Sign{Char: '•', Color: "sign_search_fg", Priority: 20})
+	}
+}
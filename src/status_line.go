@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type StatusLineSegment struct {
+	Name string
+	Text string
+}
+
+// statusLineSegments returns the text of each status line segment for a buffer. Empty segments are not drawn
+var statusLineSegments = map[string]func(window *Window) string{
+	"path": func(window *Window) string {
+		if window.CurrentBuffer.filename == "" {
+			return window.CurrentBuffer.Name
+		}
+
+		if relPath, err := filepath.Rel(fileBrowser.Root, window.CurrentBuffer.filename); err == nil && !strings.HasPrefix(relPath, "..") {
+			return relPath
+		}
+		return window.CurrentBuffer.filename
+	},
+	"modified": func(window *Window) string {
+		if window.CurrentBuffer.IsDirty() {
+			return "[+]"
+		}
+		return ""
+	},
+	"read_only": func(window *Window) string {
+		if window.CurrentBuffer.readOnly {
+			return "[RO]"
+		}
+		return ""
+	},
+	"language": func(window *Window) string {
+		if language := window.CurrentBuffer.GetLanguage(); language != nil {
+			return language.Name
+		}
+		return "plain"
+	},
+	"encoding": func(window *Window) string {
+		return window.CurrentBuffer.GetEncoding()
+	},
+	"line_ending": func(window *Window) string {
+		return window.CurrentBuffer.GetLineEnding()
+	},
+	"cursor": func(window *Window) string {
+		x, y := window.GetCursorPos2D()
+		return fmt.Sprintf("Ln %d, Col %d", y+1, x+1)
+	},
+	"selection": func(window *Window) string {
+		buffer := window.CurrentBuffer
+		if buffer.BlockSelection != nil {
+			top, left, bottom, right := buffer.BlockSelection.GetEdges()
+			return fmt.Sprintf("%dx%d selected", bottom-top+1, right-left)
+		} else if buffer.Selection != nil {
+			edge1, edge2 := buffer.GetSelectionEdges()
+			return fmt.Sprintf("%d selected", utf8.RuneCountInString(buffer.Contents[edge1:min(edge2+1, len(buffer.Contents))]))
+		}
+		return ""
+	},
+	"percentage": func(window *Window) string {
+		_, y := window.GetCursorPos2D()
+		lines := window.CurrentBuffer.GetLineCount()
+		if lines <= 1 {
+			return "All"
+		}
+		return fmt.Sprintf("%d%%", y*100/(lines-1))
+	},
+	"git_branch": func(window *Window) string {
+		return getGitBranch()
+	},
+}
+
+var gitBranch = ""
+var gitBranchRead time.Time
+
+// getGitBranch returns the checked out branch of the repository containing the working directory.
+// The branch is read at most every two seconds
+func getGitBranch() string {
+	if time.Since(gitBranchRead) < 2*time.Second {
+		return gitBranch
+	}
+	gitBranchRead = time.Now()
+	gitBranch = ""
+
+	for dir := fileBrowser.Root; ; dir = filepath.Dir(dir) {
+		data, err := os.ReadFile(filepath.Join(dir, ".git", "HEAD"))
+		if err == nil {
+			head := strings.TrimSpace(string(data))
+			if branch, ok := strings.CutPrefix(head, "ref: refs/heads/"); ok {
+				gitBranch = branch
+			} else if len(head) >= 7 {
+				// Detached head
+				gitBranch = head[:7]
+			}
+			break
+		}
+
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	return gitBranch
+}
+
+func getStatusLineSegments(window *Window, names []string) []StatusLineSegment {
+	segments := make([]StatusLineSegment, 0, len(names))
+	for _, name := range names {
+		segmentFunc, ok := statusLineSegments[name]
+		if !ok {
+			continue
+		}
+
+		if text := segmentFunc(window); text != "" {
+			segments = append(segments, StatusLineSegment{Name: name, Text: " " + text + " "})
+		}
+	}
+	return segments
+}
+
+func getStatusLineSegmentsWidth(segments []StatusLineSegment) int {
+	width := 0
+	for _, segment := range segments {
+		width += len([]rune(segment.Text))
+	}
+	return width
+}
+
+// getStatusLineSegmentStyle returns the style of a segment, falling back to the status line colors
+func getStatusLineSegmentStyle(name string) tcell.Style {
+	style := CurrentStyle.GetStyle("status_line")
+
+	if colors, ok := CurrentStyle.StatusSegments[name]; ok {
+		if colors[0] != tcell.ColorDefault {
+			style = style.Background(colors[0])
+		}
+		if colors[1] != tcell.ColorDefault {
+			style = style.Foreground(colors[1])
+		}
+	}
+	if attributes, ok := CurrentStyle.Attributes["status_"+name]; ok {
+		style = style.Attributes(attributes)
+	}
+
+	return style
+}
+
+func getStatusLineY(window *Window) int {
+	_, sizeY := window.screen.Size()
+	return sizeY - 2
+}
+
+func drawStatusLine(window *Window) {
+	screen := window.screen
+
+	statusLineStyle := CurrentStyle.GetStyle("status_line")
+
+	sizeX, _ := screen.Size()
+	y := getStatusLineY(window)
+
+	for x := 0; x < sizeX; x++ {
+		screen.SetContent(x, y, ' ', nil, statusLineStyle)
+	}
+
+	drawSegments := func(x int, segments []StatusLineSegment) {
+		for _, segment := range segments {
+			style := getStatusLineSegmentStyle(segment.Name)
+			for _, r := range segment.Text {
+				if x >= 0 && x < sizeX {
+					screen.SetContent(x, y, r, nil, style)
+				}
+				x++
+			}
+		}
+	}
+
+	left := getStatusLineSegments(window, Config.StatusLine.Left)
+	center := getStatusLineSegments(window, Config.StatusLine.Center)
+	right := getStatusLineSegments(window, Config.StatusLine.Right)
+
+	leftWidth := getStatusLineSegmentsWidth(left)
+	centerWidth := getStatusLineSegmentsWidth(center)
+	rightWidth := getStatusLineSegmentsWidth(right)
+
+	// Right segments take precedence over center segments, which take precedence over left segments
+	drawSegments(0, left)
+	if centerX := (sizeX - centerWidth) / 2; centerX >= leftWidth && centerX+centerWidth <= sizeX-rightWidth {
+		drawSegments(centerX, center)
+	}
+	drawSegments(sizeX-rightWidth, right)
+}
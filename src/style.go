@@ -5,6 +5,7 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"gopkg.in/yaml.v3"
 	"log"
+	"maps"
 	"os"
 	"path"
 	"reflect"
@@ -34,6 +35,57 @@ type TyperStyle struct {
 	MessageBarFg  tcell.Color `name:"message_bar_fg"`
 	InputBarBg    tcell.Color `name:"input_bar_bg"`
 	InputBarFg    tcell.Color `name:"input_bar_fg"`
+	BracketMatch  tcell.Color `name:"bracket_match"`
+
+	DropdownDisabled tcell.Color `name:"dropdown_disabled"`
+
+	MessageBarWarningBg tcell.Color `name:"message_bar_warning_bg"`
+	MessageBarWarningFg tcell.Color `name:"message_bar_warning_fg"`
+	MessageBarErrorBg   tcell.Color `name:"message_bar_error_bg"`
+	MessageBarErrorFg   tcell.Color `name:"message_bar_error_fg"`
+
+	StatusLineBg tcell.Color `name:"status_line_bg"`
+	StatusLineFg tcell.Color `name:"status_line_fg"`
+
+	// Background and foreground colors of status line segments
+	StatusSegments map[string][2]tcell.Color
+
+	TabBarBg       tcell.Color `name:"tab_bar_bg"`
+	TabBarFg       tcell.Color `name:"tab_bar_fg"`
+	TabBarActiveBg tcell.Color `name:"tab_bar_active_bg"`
+	TabBarActiveFg tcell.Color `name:"tab_bar_active_fg"`
+
+	FileBrowserBg  tcell.Color `name:"file_browser_bg"`
+	FileBrowserFg  tcell.Color `name:"file_browser_fg"`
+	FileBrowserSel tcell.Color `name:"file_browser_sel"`
+	FileBrowserDir tcell.Color `name:"file_browser_dir"`
+
+	ScrollbarBg       tcell.Color `name:"scrollbar_bg"`
+	ScrollbarThumb    tcell.Color `name:"scrollbar_thumb"`
+	ScrollbarCursor   tcell.Color `name:"scrollbar_cursor"`
+	ScrollbarMatch    tcell.Color `name:"scrollbar_match"`
+	ScrollbarModified tcell.Color `name:"scrollbar_modified"`
+
+	MinimapBg   tcell.Color `name:"minimap_bg"`
+	MinimapFg   tcell.Color `name:"minimap_fg"`
+	MinimapView tcell.Color `name:"minimap_view"`
+
+	CursorBg tcell.Color `name:"cursor_bg"`
+	CursorFg tcell.Color `name:"cursor_fg"`
+
+	CurrentLineBg tcell.Color `name:"current_line_bg"`
+	WhitespaceFg  tcell.Color `name:"whitespace_fg"`
+	RulerBg       tcell.Color `name:"ruler_bg"`
+
+	LineIndexCurrentBg tcell.Color `name:"line_index_current_bg"`
+	LineIndexCurrentFg tcell.Color `name:"line_index_current_fg"`
+
+	SignModifiedFg tcell.Color `name:"sign_modified_fg"`
+	SignSearchFg   tcell.Color `name:"sign_search_fg"`
+	FoldMarkerFg   tcell.Color `name:"fold_marker_fg"`
+
+	// Text attributes of slots, such as bold or underline
+	Attributes map[string]tcell.AttrMask
 }
 
 type typerStyleYaml struct {
@@ -42,10 +94,36 @@ type typerStyleYaml struct {
 	Description string `yaml:"description"`
 	StyleType   string `yaml:"style_type"`
 
+	// Name of the style to take missing colors from
+	Inherits string `yaml:"inherits"`
+
 	// Colors
-	Colors map[string]string `yaml:"colors"`
+	Colors map[string]typerStyleSlotYaml `yaml:"colors"`
+}
+
+// typerStyleSlotYaml is either a single color or a mapping of background color, foreground color and attributes
+type typerStyleSlotYaml struct {
+	Color      string
+	Bg         string
+	Fg         string
+	Attributes map[string]bool
+
+	mapping bool
 }
 
+var styleAttributes = map[string]tcell.AttrMask{
+	"bold":          tcell.AttrBold,
+	"italic":        tcell.AttrItalic,
+	"underline":     tcell.AttrUnderline,
+	"reverse":       tcell.AttrReverse,
+	"dim":           tcell.AttrDim,
+	"blink":         tcell.AttrBlink,
+	"strikethrough": tcell.AttrStrikeThrough,
+}
+
+// styleFieldIndices maps slot names to the indices of their TyperStyle fields
+var styleFieldIndices = getStyleFieldIndices()
+
 var FallbackStyle = TyperStyle{
 	Name:        "fallback",
 	Description: "Fallback style",
@@ -65,6 +143,51 @@ var FallbackStyle = TyperStyle{
 	MessageBarFg:  tcell.ColorBlack,
 	InputBarBg:    tcell.ColorWhite,
 	InputBarFg:    tcell.ColorBlack,
+	BracketMatch:  tcell.ColorTeal,
+
+	DropdownDisabled: tcell.ColorGray,
+
+	MessageBarWarningBg: tcell.ColorOlive,
+	MessageBarWarningFg: tcell.ColorBlack,
+	MessageBarErrorBg:   tcell.ColorMaroon,
+	MessageBarErrorFg:   tcell.ColorWhite,
+
+	StatusLineBg: tcell.ColorWhite,
+	StatusLineFg: tcell.ColorBlack,
+
+	TabBarBg:       tcell.ColorWhite,
+	TabBarFg:       tcell.ColorBlack,
+	TabBarActiveBg: tcell.ColorBlack,
+	TabBarActiveFg: tcell.ColorWhite,
+
+	FileBrowserBg:  tcell.ColorWhite,
+	FileBrowserFg:  tcell.ColorBlack,
+	FileBrowserSel: tcell.ColorNavy,
+	FileBrowserDir: tcell.ColorTeal,
+
+	ScrollbarBg:       tcell.ColorWhite,
+	ScrollbarThumb:    tcell.ColorGray,
+	ScrollbarCursor:   tcell.ColorBlack,
+	ScrollbarMatch:    tcell.ColorOlive,
+	ScrollbarModified: tcell.ColorGreen,
+
+	MinimapBg:   tcell.ColorBlack,
+	MinimapFg:   tcell.ColorGray,
+	MinimapView: tcell.ColorNavy,
+
+	CursorBg: tcell.ColorSilver,
+	CursorFg: tcell.ColorBlack,
+
+	CurrentLineBg: tcell.ColorBlack,
+	WhitespaceFg:  tcell.ColorGray,
+	RulerBg:       tcell.ColorNavy,
+
+	LineIndexCurrentBg: tcell.ColorWhite,
+	LineIndexCurrentFg: tcell.ColorNavy,
+
+	SignModifiedFg: tcell.ColorGreen,
+	SignSearchFg:   tcell.ColorOlive,
+	FoldMarkerFg:   tcell.ColorGray,
 }
 
 var AvailableStyles = make(map[string]TyperStyle)
@@ -76,6 +199,9 @@ func readStyles() {
 		log.Fatalf("Could not get home directory: %s", err)
 	}
 
+	// Read all style files before building styles, as styles can inherit from styles in other files
+	styleYamls := make(map[string]typerStyleYaml)
+
 	if stat, err := os.Stat(path.Join(homeDir, ".config/typer/styles/")); err == nil && stat.IsDir() {
 		entries, err := os.ReadDir(path.Join(homeDir, ".config/typer/styles/"))
 		if err != nil {
@@ -84,13 +210,13 @@ func readStyles() {
 
 		for _, entry := range entries {
 			entryPath := path.Join(homeDir, ".config/typer/styles/", entry.Name())
-			style, err := readStyleYamlFile(entryPath)
+			styleYaml, err := readStyleYamlFile(entryPath)
 			if err != nil {
 				log.Fatalf("Could not read style file (%s): %s", entryPath, err)
 			}
 
-			if _, ok := AvailableStyles[style.Name]; !ok {
-				AvailableStyles[style.Name] = style
+			if _, ok := styleYamls[styleYaml.Name]; !ok {
+				styleYamls[styleYaml.Name] = styleYaml
 			}
 		}
 	}
@@ -102,90 +228,266 @@ func readStyles() {
 
 		for _, entry := range entries {
 			entryPath := path.Join(path.Join(sysconfdir, "typer/styles/"), entry.Name())
-			style, err := readStyleYamlFile(entryPath)
+			styleYaml, err := readStyleYamlFile(entryPath)
 			if err != nil {
 				log.Fatalf("Could not read style file (%s): %s", entryPath, err)
 			}
-			if _, ok := AvailableStyles[style.Name]; !ok {
-				AvailableStyles[style.Name] = style
+			if _, ok := styleYamls[styleYaml.Name]; !ok {
+				styleYamls[styleYaml.Name] = styleYaml
 			}
 		}
 	}
+
+	for name := range styleYamls {
+		if _, err := buildStyle(styleYamls, name, nil); err != nil {
+			log.Fatalf("Could not build style (%s): %s", name, err)
+		}
+	}
 }
 
-func readStyleYamlFile(filepath string) (TyperStyle, error) {
+func readStyleYamlFile(filepath string) (typerStyleYaml, error) {
 	styleYaml := typerStyleYaml{}
 
 	data, err := os.ReadFile(filepath)
 	if err != nil {
-		return TyperStyle{}, fmt.Errorf("could not read file: %s", err)
+		return typerStyleYaml{}, fmt.Errorf("could not read file: %s", err)
 	}
 	err = yaml.Unmarshal(data, &styleYaml)
 	if err != nil {
-		return TyperStyle{}, fmt.Errorf("could not unmarshal style: %s", err)
+		return typerStyleYaml{}, fmt.Errorf("could not unmarshal style: %s", err)
 	}
 
-	style := TyperStyle{
-		Name:        styleYaml.Name,
-		Description: styleYaml.Description,
-		StyleType:   styleYaml.StyleType,
+	// Validate slot names
+	for name, slot := range styleYaml.Colors {
+		if !slot.mapping {
+			if !styleSlotExists(name) {
+				return typerStyleYaml{}, fmt.Errorf("unknown slot (%s)", name)
+			}
+			continue
+		}
+
+		// Mappings that only set attributes are allowed on every slot
+		if slot.Bg == "" && slot.Fg == "" && styleSlotExists(name) {
+			continue
+		}
+
+		if !styleSlotExists(name+"_bg") && !styleSlotExists(name+"_fg") {
+			return typerStyleYaml{}, fmt.Errorf("unknown slot (%s)", name)
+		} else if slot.Bg != "" && !styleSlotExists(name+"_bg") {
+			return typerStyleYaml{}, fmt.Errorf("slot (%s) has no background color", name)
+		} else if slot.Fg != "" && !styleSlotExists(name+"_fg") {
+			return typerStyleYaml{}, fmt.Errorf("slot (%s) has no foreground color", name)
+		}
 	}
 
-	for name, colorStr := range styleYaml.Colors {
-		var color tcell.Color
+	return styleYaml, nil
+}
 
-		if n, err := strconv.Atoi(colorStr); err == nil && n >= 0 && n < 256 {
-			color = tcell.ColorValid + tcell.Color(n)
-		} else if strings.HasPrefix(colorStr, "#") && len(colorStr) == 7 {
-			n, err := strconv.ParseInt(colorStr[1:], 16, 32)
-			if err != nil {
-				return TyperStyle{}, fmt.Errorf("could not parse color (%s): %s", colorStr, err)
-			}
+func (slot *typerStyleSlotYaml) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&slot.Color)
+	} else if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: slot must be a color or a mapping", value.Line)
+	}
+
+	slot.mapping = true
+	slot.Attributes = make(map[string]bool)
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key, content := value.Content[i].Value, value.Content[i+1]
 
-			color = tcell.NewHexColor(int32(n))
-		} else if c, ok := tcell.ColorNames[colorStr]; ok {
-			color = c
+		var err error
+		if key == "bg" {
+			err = content.Decode(&slot.Bg)
+		} else if key == "fg" {
+			err = content.Decode(&slot.Fg)
+		} else if _, ok := styleAttributes[key]; ok {
+			var enabled bool
+			err = content.Decode(&enabled)
+			slot.Attributes[key] = enabled
 		} else {
-			return TyperStyle{}, fmt.Errorf("could not parse color (%s): %s", colorStr, err)
+			return fmt.Errorf("line %d: unknown slot key (%s)", value.Content[i].Line, key)
 		}
+		if err != nil {
+			return err
+		}
+	}
 
-		pt := reflect.TypeOf(&style)
-		t := pt.Elem()
-		pv := reflect.ValueOf(&style)
-		v := pv.Elem()
+	return nil
+}
 
-		for i := 0; i < t.NumField(); i++ {
-			field := v.Field(i)
+// buildStyle builds a style and the styles it inherits from. Missing colors are taken from the parent style or the fallback style
+func buildStyle(styleYamls map[string]typerStyleYaml, name string, inheritedBy []string) (TyperStyle, error) {
+	if style, ok := AvailableStyles[name]; ok {
+		return style, nil
+	}
 
-			if tag, ok := t.Field(i).Tag.Lookup("name"); ok && tag == name {
-				field.Set(reflect.ValueOf(color))
+	styleYaml, ok := styleYamls[name]
+	if !ok {
+		return TyperStyle{}, fmt.Errorf("style (%s) does not exist", name)
+	}
+	if slices.Contains(inheritedBy, name) {
+		return TyperStyle{}, fmt.Errorf("inheritance loop (%s)", strings.Join(append(inheritedBy, name), " -> "))
+	}
+
+	parent := FallbackStyle
+	if styleYaml.Inherits != "" {
+		var err error
+		parent, err = buildStyle(styleYamls, styleYaml.Inherits, append(inheritedBy, name))
+		if err != nil {
+			return TyperStyle{}, err
+		}
+	}
+
+	style := parent
+	style.Name = styleYaml.Name
+	style.Description = styleYaml.Description
+	if styleYaml.StyleType != "" {
+		style.StyleType = styleYaml.StyleType
+	}
+
+	style.StatusSegments = make(map[string][2]tcell.Color)
+	maps.Copy(style.StatusSegments, parent.StatusSegments)
+	style.Attributes = make(map[string]tcell.AttrMask)
+	maps.Copy(style.Attributes, parent.Attributes)
+
+	for slotName, slot := range styleYaml.Colors {
+		if !slot.mapping {
+			if err := style.setColor(slotName, slot.Color); err != nil {
+				return TyperStyle{}, err
+			}
+			continue
+		}
+
+		if slot.Bg != "" {
+			if err := style.setColor(slotName+"_bg", slot.Bg); err != nil {
+				return TyperStyle{}, err
+			}
+		}
+		if slot.Fg != "" {
+			if err := style.setColor(slotName+"_fg", slot.Fg); err != nil {
+				return TyperStyle{}, err
+			}
+		}
+
+		attributes := style.Attributes[slotName]
+		for attribute, enabled := range slot.Attributes {
+			if enabled {
+				attributes |= styleAttributes[attribute]
+			} else {
+				attributes &^= styleAttributes[attribute]
 			}
 		}
+		style.Attributes[slotName] = attributes
 	}
 
+	AvailableStyles[name] = style
+
 	return style, nil
 }
 
-func SetCurrentStyle(screen tcell.Screen, styleName string) bool {
-	availableTypes := make([]string, 1)
-	availableTypes[0] = "8-color"
-	if screen.Colors() >= 16 {
-		availableTypes = append(availableTypes, "16-color")
+func parseStyleColor(colorStr string) (tcell.Color, error) {
+	if n, err := strconv.Atoi(colorStr); err == nil && n >= 0 && n < 256 {
+		return tcell.ColorValid + tcell.Color(n), nil
+	} else if strings.HasPrefix(colorStr, "#") && len(colorStr) == 7 {
+		n, err := strconv.ParseInt(colorStr[1:], 16, 32)
+		if err != nil {
+			return tcell.ColorDefault, fmt.Errorf("could not parse color (%s): %s", colorStr, err)
+		}
+
+		return tcell.NewHexColor(int32(n)), nil
+	} else if c, ok := tcell.ColorNames[colorStr]; ok {
+		return c, nil
 	}
-	if screen.Colors() >= 256 {
-		availableTypes = append(availableTypes, "256-color")
+
+	return tcell.ColorDefault, fmt.Errorf("could not parse color (%s)", colorStr)
+}
+
+func getStyleFieldIndices() map[string]int {
+	indices := make(map[string]int)
+
+	t := reflect.TypeOf(TyperStyle{})
+	for i := 0; i < t.NumField(); i++ {
+		if tag, ok := t.Field(i).Tag.Lookup("name"); ok {
+			indices[tag] = i
+		}
 	}
-	if screen.Colors() >= 16777216 {
-		availableTypes = append(availableTypes, "true-color")
+
+	return indices
+}
+
+// getStatusSegmentSlot returns the segment and color index of status line segment slots, named status_<segment>_bg and status_<segment>_fg
+func getStatusSegmentSlot(name string) (string, int, bool) {
+	segment, ok := strings.CutPrefix(name, "status_")
+	if !ok {
+		return "", 0, false
 	}
 
-	if style, ok := AvailableStyles[styleName]; ok && slices.Index(availableTypes, style.StyleType) != -1 {
-		CurrentStyle = style
+	if segmentName, ok := strings.CutSuffix(segment, "_bg"); ok {
+		_, exists := statusLineSegments[segmentName]
+		return segmentName, 0, exists
+	} else if segmentName, ok := strings.CutSuffix(segment, "_fg"); ok {
+		_, exists := statusLineSegments[segmentName]
+		return segmentName, 1, exists
+	}
 
-		screen.SetStyle(tcell.StyleDefault.Foreground(CurrentStyle.BufferAreaFg).Background(CurrentStyle.BufferAreaBg))
-		screen.Sync()
+	return "", 0, false
+}
 
+func styleSlotExists(name string) bool {
+	if _, ok := styleFieldIndices[name]; ok {
 		return true
 	}
-	return false
+	_, _, ok := getStatusSegmentSlot(name)
+	return ok
+}
+
+func (style *TyperStyle) setColor(name string, colorStr string) error {
+	color, err := parseStyleColor(colorStr)
+	if err != nil {
+		return err
+	}
+
+	if i, ok := styleFieldIndices[name]; ok {
+		reflect.ValueOf(style).Elem().Field(i).Set(reflect.ValueOf(color))
+	} else if segment, index, ok := getStatusSegmentSlot(name); ok {
+		colors := style.StatusSegments[segment]
+		colors[index] = color
+		style.StatusSegments[segment] = colors
+	} else {
+		return fmt.Errorf("unknown slot (%s)", name)
+	}
+
+	return nil
+}
+
+// GetColor returns the color of a slot, such as "sign_modified_fg", or the default color if the slot does not exist
+func (style *TyperStyle) GetColor(name string) tcell.Color {
+	if i, ok := styleFieldIndices[name]; ok {
+		return reflect.ValueOf(style).Elem().Field(i).Interface().(tcell.Color)
+	}
+	return tcell.ColorDefault
+}
+
+// GetStyle returns the colors and attributes of a slot, for example "buffer_area" for buffer_area_bg and buffer_area_fg
+func (style *TyperStyle) GetStyle(slot string) tcell.Style {
+	return tcell.StyleDefault.
+		Background(style.GetColor(slot + "_bg")).
+		Foreground(style.GetColor(slot + "_fg")).
+		Attributes(style.Attributes[slot])
+}
+
+func SetCurrentStyle(screen tcell.Screen, styleName string) bool {
+	style, ok := AvailableStyles[styleName]
+	if !ok {
+		return false
+	}
+
+	// The style type is only a hint, colors are converted to ones the terminal can show
+	terminalColors = getTerminalColors(screen)
+	CurrentStyle = convertStyleColors(style, terminalColors)
+
+	screen.SetStyle(CurrentStyle.GetStyle("buffer_area"))
+	screen.Sync()
+
+	return true
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestStyleAttributeOnlyMappings(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.yml")
+	data := `name: attribute-test
+colors:
+  bracket_match: { bold: true }
+  line_index_current: { underline: true }
+  no_such_slot: { bold: true }
+`
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := readStyleYamlFile(file); err == nil {
+		t.Fatal("expected unknown slot to be rejected")
+	}
+
+	data = data[:len(data)-len("  no_such_slot: { bold: true }\n")]
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	styleYaml, err := readStyleYamlFile(file)
+	if err != nil {
+		t.Fatalf("expected attribute-only mappings to be accepted, got (%s)", err)
+	}
+
+	style, err := buildStyle(map[string]typerStyleYaml{styleYaml.Name: styleYaml}, styleYaml.Name, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if style.Attributes["bracket_match"] != tcell.AttrBold {
+		t.Errorf("expected bracket_match to be bold, got attributes (%d)", style.Attributes["bracket_match"])
+	}
+	if style.Attributes["line_index_current"]&tcell.AttrUnderline == 0 {
+		t.Errorf("expected line_index_current to be underlined, got attributes (%d)", style.Attributes["line_index_current"])
+	}
+	if style.BracketMatch != FallbackStyle.BracketMatch {
+		t.Error("expected bracket_match color to be inherited")
+	}
+}
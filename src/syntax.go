@@ -0,0 +1,230 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type Language struct {
+	Name              string
+	Extensions        []string
+	Filenames         []string
+	LineComment       string
+	BlockCommentStart string
+	BlockCommentEnd   string
+	StringDelimiters  string
+
+	// Fold blocks between brackets instead of by indentation
+	FoldBrackets bool
+}
+
+var Languages = []Language{
+	{
+		Name:              "go",
+		Extensions:        []string{".go"},
+		LineComment:       "//",
+		BlockCommentStart: "/*",
+		BlockCommentEnd:   "*/",
+		StringDelimiters:  "\"'`",
+		FoldBrackets:      true,
+	},
+	{
+		Name:              "c",
+		Extensions:        []string{".c", ".h", ".cpp", ".hpp", ".cc", ".cxx"},
+		LineComment:       "//",
+		BlockCommentStart: "/*",
+		BlockCommentEnd:   "*/",
+		StringDelimiters:  "\"'",
+		FoldBrackets:      true,
+	},
+	{
+		Name:              "rust",
+		Extensions:        []string{".rs"},
+		LineComment:       "//",
+		BlockCommentStart: "/*",
+		BlockCommentEnd:   "*/",
+		StringDelimiters:  "\"",
+		FoldBrackets:      true,
+	},
+	{
+		Name:              "java",
+		Extensions:        []string{".java", ".kt", ".cs"},
+		LineComment:       "//",
+		BlockCommentStart: "/*",
+		BlockCommentEnd:   "*/",
+		StringDelimiters:  "\"'",
+		FoldBrackets:      true,
+	},
+	{
+		Name:              "javascript",
+		Extensions:        []string{".js", ".mjs", ".ts", ".jsx", ".tsx", ".json"},
+		LineComment:       "//",
+		BlockCommentStart: "/*",
+		BlockCommentEnd:   "*/",
+		StringDelimiters:  "\"'`",
+		FoldBrackets:      true,
+	},
+	{
+		Name:             "python",
+		Extensions:       []string{".py"},
+		LineComment:      "#",
+		StringDelimiters: "\"'",
+	},
+	{
+		Name:             "shell",
+		Extensions:       []string{".sh", ".bash", ".zsh"},
+		Filenames:        []string{".bashrc", ".zshrc", ".profile"},
+		LineComment:      "#",
+		StringDelimiters: "\"'",
+	},
+	{
+		Name:             "yaml",
+		Extensions:       []string{".yml", ".yaml"},
+		LineComment:      "#",
+		StringDelimiters: "\"'",
+	},
+	{
+		Name:             "makefile",
+		Filenames:        []string{"Makefile", "makefile", "GNUmakefile"},
+		LineComment:      "#",
+		StringDelimiters: "\"'",
+	},
+	{
+		Name:              "html",
+		Extensions:        []string{".html", ".htm", ".xml"},
+		BlockCommentStart: "<!--",
+		BlockCommentEnd:   "-->",
+		StringDelimiters:  "\"'",
+	},
+	{
+		Name:              "css",
+		Extensions:        []string{".css"},
+		BlockCommentStart: "/*",
+		BlockCommentEnd:   "*/",
+		StringDelimiters:  "\"'",
+		FoldBrackets:      true,
+	},
+}
+
+func GetLanguageByName(name string) *Language {
+	for i, language := range Languages {
+		if language.Name == name {
+			return &Languages[i]
+		}
+	}
+	return nil
+}
+
+// GetLanguage returns the language of the buffer based on its filename, or nil if it is unknown
+func (buffer *Buffer) GetLanguage() *Language {
+	if buffer.filename == "" {
+		return nil
+	}
+
+	base := filepath.Base(buffer.filename)
+	ext := strings.ToLower(filepath.Ext(base))
+	for i, language := range Languages {
+		if slices.Contains(language.Filenames, base) || (ext != "" && slices.Contains(language.Extensions, ext)) {
+			return &Languages[i]
+		}
+	}
+
+	return nil
+}
+
+// getCodeMask returns a slice marking which positions of the buffer are code and not part of a string or comment.
+// The mask is cached until the buffer contents change
+func (buffer *Buffer) getCodeMask() []bool {
+	mask, _ := buffer.getCodeRegions()
+	return mask
+}
+
+// getCodeRegions returns the code mask along with the region of every position. Code is region 0, and every string
+// and comment is numbered as its own region, so positions are in the same string or comment if their regions are equal
+func (buffer *Buffer) getCodeRegions() ([]bool, []int) {
+	language := buffer.GetLanguage()
+	if language == nil {
+		return nil, nil
+	}
+
+	if buffer.codeMask != nil && buffer.codeMaskRevision == buffer.revision {
+		return buffer.codeMask, buffer.codeRegions
+	}
+
+	contents := buffer.Contents
+	mask := make([]bool, len(contents)+1)
+	regions := make([]int, len(contents)+1)
+	region := 0
+
+	// Number the positions from start to i as a new region
+	markRegion := func(start, i int) {
+		region++
+		for j := start; j < min(i, len(contents)); j++ {
+			regions[j] = region
+		}
+	}
+
+	for i := 0; i < len(contents); {
+		switch {
+		case language.LineComment != "" && strings.HasPrefix(contents[i:], language.LineComment):
+			// Skip to end of line
+			start := i
+			end := strings.IndexByte(contents[i:], '\n')
+			if end == -1 {
+				end = len(contents) - i
+			}
+			i += end
+			markRegion(start, i)
+		case language.BlockCommentStart != "" && strings.HasPrefix(contents[i:], language.BlockCommentStart):
+			// Skip to end of block comment
+			start := i
+			end := strings.Index(contents[i+len(language.BlockCommentStart):], language.BlockCommentEnd)
+			if end == -1 {
+				i = len(contents)
+			} else {
+				i += len(language.BlockCommentStart) + end + len(language.BlockCommentEnd)
+			}
+			markRegion(start, i)
+		case strings.IndexByte(language.StringDelimiters, contents[i]) != -1:
+			// Skip to end of string, keeping the delimiters as code
+			delimiter := contents[i]
+			mask[i] = true
+			i++
+			start := i
+			for i < len(contents) && contents[i] != delimiter {
+				if contents[i] == '\\' {
+					i++
+				} else if contents[i] == '\n' && delimiter != '`' {
+					break
+				}
+				i++
+			}
+			markRegion(start, i)
+			if i < len(contents) {
+				mask[i] = true
+				i++
+			}
+		default:
+			mask[i] = true
+			i++
+		}
+	}
+	mask[len(contents)] = true
+
+	buffer.codeMask = mask
+	buffer.codeRegions = regions
+	buffer.codeMaskRevision = buffer.revision
+
+	return mask, regions
+}
+
+// IsCode returns whether pos is outside strings and comments. Positions in buffers of unknown languages are always code
+func (buffer *Buffer) IsCode(pos int) bool {
+	mask := buffer.getCodeMask()
+	if mask == nil || pos < 0 || pos >= len(mask) {
+		return true
+	}
+
+	return mask[pos]
+}
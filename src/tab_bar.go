@@ -0,0 +1,176 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"path/filepath"
+	"slices"
+)
+
+type tabBarTab struct {
+	buffer *Buffer
+	x1, x2 int
+}
+
+var tabBarOffset = 0
+var tabBarLastBuffer *Buffer
+
+func getTabBarY(window *Window) int {
+	if window.ShowTopMenu {
+		return 1
+	}
+	return 0
+}
+
+func getTabLabel(buffer *Buffer) string {
+	name := buffer.Name
+	if buffer.filename != "" {
+		name = filepath.Base(name)
+	}
+
+	if buffer.IsDirty() {
+		return " " + name + " + "
+	}
+	return " " + name + " "
+}
+
+// getTabBarLayout returns the visible tabs and whether tabs are hidden to the left or right.
+// The first and last column are reserved for scroll arrows
+func getTabBarLayout(window *Window) ([]tabBarTab, bool, bool) {
+	sizeX, _ := window.screen.Size()
+
+	tabBarOffset = min(max(tabBarOffset, 0), max(len(Buffers)-1, 0))
+
+	// Scroll to current buffer when it changes
+	if window.CurrentBuffer != tabBarLastBuffer {
+		tabBarLastBuffer = window.CurrentBuffer
+
+		if index := slices.Index(Buffers, window.CurrentBuffer); index != -1 {
+			if index < tabBarOffset {
+				tabBarOffset = index
+			}
+
+			for tabBarOffset < index {
+				width := 0
+				for _, buffer := range Buffers[tabBarOffset : index+1] {
+					width += len([]rune(getTabLabel(buffer))) + 1
+				}
+				if width <= sizeX-2 {
+					break
+				}
+				tabBarOffset++
+			}
+		}
+	}
+
+	tabs := make([]tabBarTab, 0)
+	x := 1
+	i := tabBarOffset
+	for ; i < len(Buffers); i++ {
+		width := len([]rune(getTabLabel(Buffers[i])))
+		if x+width > sizeX-1 && len(tabs) > 0 {
+			break
+		}
+
+		tabs = append(tabs, tabBarTab{buffer: Buffers[i], x1: x, x2: x + width - 1})
+		x += width + 1
+	}
+
+	return tabs, tabBarOffset > 0, i < len(Buffers)
+}
+
+func ScrollTabBar(delta int) {
+	tabBarOffset = min(max(tabBarOffset+delta, 0), max(len(Buffers)-1, 0))
+}
+
+// MoveBuffer moves the current buffer by delta positions in the buffer list
+func (window *Window) MoveBuffer(delta int) bool {
+	index := slices.Index(Buffers, window.CurrentBuffer)
+	newIndex := index + delta
+	if index == -1 || newIndex < 0 || newIndex >= len(Buffers) {
+		return false
+	}
+
+	Buffers[index], Buffers[newIndex] = Buffers[newIndex], Buffers[index]
+
+	// Keep moved tab visible
+	tabBarLastBuffer = nil
+
+	return true
+}
+
+func handleTabBarMouse(window *Window, ev *tcell.EventMouse) {
+	mouseX, _ := ev.Position()
+	sizeX, _ := window.screen.Size()
+
+	switch ev.Buttons() {
+	case tcell.WheelUp, tcell.WheelLeft:
+		ScrollTabBar(-1)
+		return
+	case tcell.WheelDown, tcell.WheelRight:
+		ScrollTabBar(1)
+		return
+	}
+
+	tabs, hiddenLeft, hiddenRight := getTabBarLayout(window)
+
+	if ev.Buttons() == tcell.ButtonPrimary {
+		// Scroll arrows
+		if mouseX == 0 && hiddenLeft {
+			ScrollTabBar(-1)
+			return
+		} else if mouseX == sizeX-1 && hiddenRight {
+			ScrollTabBar(1)
+			return
+		}
+	}
+
+	for _, tab := range tabs {
+		if mouseX < tab.x1 || mouseX > tab.x2 {
+			continue
+		}
+
+		if ev.Buttons() == tcell.ButtonPrimary {
+			window.CurrentBuffer = tab.buffer
+			window.CursorMode = CursorModeBuffer
+		} else if ev.Buttons() == tcell.ButtonMiddle {
+			window.CloseBuffer(tab.buffer)
+		}
+		return
+	}
+}
+
+func drawTabBar(window *Window) {
+	screen := window.screen
+
+	tabBarStyle := CurrentStyle.GetStyle("tab_bar")
+	activeTabStyle := CurrentStyle.GetStyle("tab_bar_active")
+
+	sizeX, _ := screen.Size()
+	y := getTabBarY(window)
+
+	for x := 0; x < sizeX; x++ {
+		screen.SetContent(x, y, ' ', nil, tabBarStyle)
+	}
+
+	tabs, hiddenLeft, hiddenRight := getTabBarLayout(window)
+
+	for _, tab := range tabs {
+		style := tabBarStyle
+		if tab.buffer == window.CurrentBuffer {
+			style = activeTabStyle
+		}
+
+		label := []rune(getTabLabel(tab.buffer))
+		for i := 0; i < len(label) && tab.x1+i < sizeX-1; i++ {
+			screen.SetContent(tab.x1+i, y, label[i], nil, style)
+		}
+	}
+
+	// Draw scroll arrows
+	if hiddenLeft {
+		screen.SetContent(0, y, '<', nil, tabBarStyle)
+	}
+	if hiddenRight {
+		screen.SetContent(sizeX-1, y, '>', nil, tabBarStyle)
+	}
+}
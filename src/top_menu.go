@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"github.com/gdamore/tcell/v2"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -16,100 +15,100 @@ type TopMenuButton struct {
 var TopMenuButtons = make([]TopMenuButton, 0)
 
 func initTopMenu() {
-	// Buttons
-	fileButton := TopMenuButton{
-		Name: "File",
-		Action: func(window *Window) {
-			ClearDropdowns()
-
-			y := 0
-			if window.ShowTopMenu {
-				y++
-			}
-
-			d := CreateDropdownMenu([]string{"New", "Save", "Open", "Close", "Quit"}, 0, y, 0, func(i int) {
-				switch i {
-				case 0:
-					RunCommand(window, "new-buffer")
-				case 1:
-					RunCommand(window, "save")
-				case 2:
-					RunCommand(window, "open")
-				case 3:
-					RunCommand(window, "close-buffer")
-				case 4:
-					RunCommand(window, "quit")
-				}
-				ClearDropdowns()
-			})
-			ActiveDropdown = d
-			window.CursorMode = CursorModeDropdown
-		},
+	TopMenuButtons = make([]TopMenuButton, 0, len(TopMenu.Menus))
+
+	// Create a button for each menu
+	for _, menu := range TopMenu.Menus {
+		TopMenuButtons = append(TopMenuButtons, TopMenuButton{
+			Name: menu.Name,
+			Action: func(window *Window) {
+				OpenTopMenuDropdown(window, menu.Name, GetDropdownItems(window, menu.Items))
+			},
+		})
 	}
-	EditButton := TopMenuButton{
-		Name: "Edit",
-		Action: func(window *Window) {
-			ClearDropdowns()
-
-			y := 0
-			if window.ShowTopMenu {
-				y++
-			}
-
-			d := CreateDropdownMenu([]string{"Cut", "Copy", "Paste"}, 0, y, 0, func(i int) {
-				switch i {
-				case 0:
-					RunCommand(window, "cut")
-				case 1:
-					RunCommand(window, "copy")
-				case 2:
-					RunCommand(window, "paste")
-				}
-				ClearDropdowns()
-				window.CursorMode = CursorModeBuffer
-			})
-			ActiveDropdown = d
-			window.CursorMode = CursorModeDropdown
-		},
+}
+
+// OpenTopMenu runs the action of the top menu button with the given name
+func OpenTopMenu(window *Window, name string) bool {
+	for _, button := range TopMenuButtons {
+		if strings.EqualFold(button.Name, name) {
+			button.Action(window)
+			return true
+		}
 	}
-	Buffers := TopMenuButton{
-		Name: "Buffers",
-		Action: func(window *Window) {
-			ClearDropdowns()
-
-			y := 0
-			if window.ShowTopMenu {
-				y++
-			}
-
-			buffersSlice := make([]string, 0)
-			for i, buffer := range Buffers {
-				if window.CurrentBuffer == buffer {
-					buffersSlice = append(buffersSlice, fmt.Sprintf("[%d] * %s", i+1, buffer.Name))
-				} else {
-					buffersSlice = append(buffersSlice, fmt.Sprintf("[%d] %s", i+1, buffer.Name))
-				}
-			}
-
-			d := CreateDropdownMenu(buffersSlice, 0, y, 0, func(i int) {
-				window.CurrentBuffer = Buffers[i]
+	return false
+}
+
+// OpenTopMenuDropdown opens a dropdown below the top menu button with the given name
+func OpenTopMenuDropdown(window *Window, name string, items []DropdownItem) {
+	ClearDropdowns()
+
+	y := 0
+	if window.ShowTopMenu {
+		y++
+	}
+
+	d := CreateDropdownMenu(items, getTopMenuButtonX(name)-1, y, 0)
+	if d == nil {
+		window.CursorMode = CursorModeBuffer
+		return
+	}
+	ActiveDropdown = d
+	window.CursorMode = CursorModeDropdown
+}
+
+// getBufferDropdownItems returns an item for every open buffer, with the current buffer checked
+func getBufferDropdownItems(window *Window) []DropdownItem {
+	items := make([]DropdownItem, 0, len(Buffers))
+	for i, buffer := range Buffers {
+		label := fmt.Sprintf("[%d] %s", i+1, buffer.Name)
+		if window.CurrentBuffer == buffer {
+			label = fmt.Sprintf("[%d] * %s", i+1, buffer.Name)
+		}
+
+		items = append(items, DropdownItem{
+			Label: label,
+			Action: func(window *Window) {
+				window.CurrentBuffer = buffer
 				PrintMessage(window, fmt.Sprintf("Set current buffer to '%s'.", window.CurrentBuffer.Name))
-				ClearDropdowns()
-				window.CursorMode = CursorModeBuffer
-			})
-			ActiveDropdown = d
-			window.CursorMode = CursorModeDropdown
-		},
+			},
+		})
 	}
+	return items
+}
 
-	// Append buttons
-	TopMenuButtons = append(TopMenuButtons, fileButton, EditButton, Buffers)
+// getTopMenuButtonX returns the column the name of a top menu button starts at
+func getTopMenuButtonX(name string) int {
+	currentX := 1
+	for _, button := range TopMenuButtons {
+		if button.Name == name {
+			return currentX
+		}
+		currentX += len(button.Name) + 1
+	}
+	return 1
+}
+
+// handleTopMenuClick opens the dropdown of the clicked button and closes open dropdowns otherwise
+func handleTopMenuClick(window *Window, mouseX int) {
+	if window.CursorMode == CursorModeDropdown {
+		ClearDropdowns()
+		window.CursorMode = CursorModeBuffer
+	}
+
+	for _, button := range TopMenuButtons {
+		buttonX := getTopMenuButtonX(button.Name)
+		if mouseX >= buttonX && mouseX < buttonX+len(button.Name) {
+			button.Action(window)
+			return
+		}
+	}
 }
 
 func drawTopMenu(window *Window) {
 	screen := window.screen
 
-	topMenuStyle := tcell.StyleDefault.Background(CurrentStyle.TopMenuBg).Foreground(CurrentStyle.TopMenuFg)
+	topMenuStyle := CurrentStyle.GetStyle("top_menu")
 
 	sizeX, _ := screen.Size()
 
@@ -123,9 +122,9 @@ func drawTopMenu(window *Window) {
 		currentX += len(button.Name) + 1
 	}
 
-	// Draw buffer info
+	// Draw buffer info if the status line is hidden
 	bufferInfoMsg := getBufferInfoMsg(window)
-	if sizeX-len(bufferInfoMsg)-1 > currentX+2 {
+	if !window.ShowStatusLine && sizeX-len(bufferInfoMsg)-1 > currentX+2 {
 		drawText(screen, sizeX-len(bufferInfoMsg)-1, 0, sizeX-1, 0, topMenuStyle, bufferInfoMsg)
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 )
@@ -17,19 +18,36 @@ const (
 	CursorModeBuffer
 	CursorModeDropdown
 	CursorModeInputBar
+	CursorModeCommandPalette
+	CursorModeFileBrowser
+	CursorModeFileFinder
 )
 
 var CursorModeNames = map[CursorMode]string{
-	CursorModeDisabled: "disabled",
-	CursorModeBuffer:   "buffer",
-	CursorModeDropdown: "dropdown",
-	CursorModeInputBar: "input_bar",
+	CursorModeDisabled:       "disabled",
+	CursorModeBuffer:         "buffer",
+	CursorModeDropdown:       "dropdown",
+	CursorModeInputBar:       "input_bar",
+	CursorModeCommandPalette: "command_palette",
+	CursorModeFileBrowser:    "file_browser",
+	CursorModeFileFinder:     "file_finder",
 }
 
 type Window struct {
-	ShowTopMenu   bool
-	ShowLineIndex bool
-	CursorMode    CursorMode
+	ShowTopMenu     bool
+	ShowTabBar      bool
+	ShowStatusLine  bool
+	ShowLineIndex   bool
+	ShowFileBrowser bool
+	ShowScrollbar   bool
+	ShowMinimap     bool
+	SoftWrap        bool
+	CursorMode      CursorMode
+
+	HighlightCurrentLine bool
+	ShowWhitespace       bool
+	ShowRulers           bool
+	LineNumbers          string
 
 	Clipboard string
 
@@ -42,12 +60,31 @@ type Window struct {
 
 var mouseHeld = false
 var lastClick int64 = 0
+var lastClickPos = -1
+var clickCount = 0
+var altClickLine, altClickCol = -1, -1
+
+// Set while a selection is dragged from the text area or line index
+var dragSelecting = false
+var dragMouseX, dragMouseY = 0, 0
+var dragScrolling = false
 
 func CreateWindow() (*Window, error) {
 	window := Window{
-		ShowTopMenu:   Config.ShowTopMenu,
-		ShowLineIndex: Config.ShowLineIndex,
-		CursorMode:    CursorModeBuffer,
+		ShowTopMenu:     Config.ShowTopMenu,
+		ShowTabBar:      Config.ShowTabBar,
+		ShowStatusLine:  Config.ShowStatusLine,
+		ShowLineIndex:   Config.ShowLineIndex,
+		ShowFileBrowser: Config.ShowFileBrowser,
+		ShowScrollbar:   Config.ShowScrollbar,
+		ShowMinimap:     Config.ShowMinimap,
+		SoftWrap:        Config.SoftWrap,
+		CursorMode:      CursorModeBuffer,
+
+		HighlightCurrentLine: Config.HighlightCurrentLine,
+		ShowWhitespace:       Config.ShowWhitespace,
+		ShowRulers:           Config.ShowRulers,
+		LineNumbers:          Config.LineNumbers,
 
 		CurrentBuffer: nil,
 
@@ -83,14 +120,21 @@ func CreateWindow() (*Window, error) {
 		// Try to set screen style to selected fallback one
 		if ok := SetCurrentStyle(screen, Config.FallbackStyle); !ok {
 			// Use hard-coded fallback style
-			screen.SetStyle(tcell.StyleDefault.Foreground(CurrentStyle.BufferAreaFg).Background(CurrentStyle.BufferAreaBg))
-			PrintMessage(&window, "Could not set style either to selected one nor to fallback one!")
+			terminalColors = getTerminalColors(screen)
+			CurrentStyle = convertStyleColors(CurrentStyle, terminalColors)
+			screen.SetStyle(CurrentStyle.GetStyle("buffer_area"))
+			PrintError(&window, "Could not set style either to selected one nor to fallback one!")
 		}
 	}
 
 	// Initialize top menu
 	initTopMenu()
 
+	// Read file browser contents
+	if window.ShowFileBrowser {
+		fileBrowser.Refresh()
+	}
+
 	return &window, nil
 }
 
@@ -103,6 +147,16 @@ func (window *Window) Draw() {
 		drawTopMenu(window)
 	}
 
+	// Draw tab bar
+	if window.ShowTabBar {
+		drawTabBar(window)
+	}
+
+	// Draw file browser
+	if window.ShowFileBrowser {
+		drawFileBrowser(window)
+	}
+
 	// Draw line index
 	if window.ShowLineIndex {
 		drawLineIndex(window)
@@ -111,6 +165,22 @@ func (window *Window) Draw() {
 	// Draw current buffer
 	if window.CurrentBuffer != nil {
 		drawBuffer(window)
+		drawBufferDecorations(window)
+	}
+
+	// Draw minimap
+	if window.ShowMinimap && window.CurrentBuffer != nil {
+		drawMinimap(window)
+	}
+
+	// Draw scrollbar
+	if window.ShowScrollbar && window.CurrentBuffer != nil {
+		drawScrollbar(window)
+	}
+
+	// Draw status line
+	if window.ShowStatusLine {
+		drawStatusLine(window)
 	}
 
 	// Draw input bar
@@ -124,10 +194,19 @@ func (window *Window) Draw() {
 	// Draw dropdowns
 	drawDropdowns(window)
 
+	// Draw command palette
+	drawCommandPalette(window)
+
+	// Draw file finder
+	drawFileFinder(window)
+
 	// Draw cursor
 	if window.CursorMode == CursorModeInputBar {
-		_, sizeY := window.screen.Size()
-		window.screen.ShowCursor(len(currentInputRequest.Text)+len(currentInputRequest.input)+1, sizeY-1)
+		window.screen.ShowCursor(getInputBarCursorPos(window))
+	} else if window.CursorMode == CursorModeCommandPalette {
+		window.screen.ShowCursor(getCommandPaletteCursorPos(window))
+	} else if window.CursorMode == CursorModeFileFinder {
+		window.screen.ShowCursor(getFileFinderCursorPos(window))
 	} else {
 		window.screen.HideCursor()
 	}
@@ -150,377 +229,460 @@ func (window *Window) ProcessEvents() {
 	case *tcell.EventKey:
 		window.handleKeyInput(ev)
 	}
+
+	// Run callbacks queued during the event
+	window.runQueuedCallbacks()
+
+	// Signs are only recomputed if the event changed the contents or the search
+	if Config.ShowSigns {
+		window.CurrentBuffer.updateSigns()
+	}
+}
+
+var queuedCallbacks = make([]func(), 0)
+var queuedCallbacksMutex sync.Mutex
+
+// RunOnMainLoop queues callback to be run by the event loop after the current event.
+// It is safe to call from other goroutines and must be used by them to access editor state
+func (window *Window) RunOnMainLoop(callback func()) {
+	queuedCallbacksMutex.Lock()
+	queuedCallbacks = append(queuedCallbacks, callback)
+	queuedCallbacksMutex.Unlock()
+
+	// Wake up event loop. If the event queue is full, the callbacks run after one of the queued events
+	window.screen.PostEvent(tcell.NewEventInterrupt(nil))
+}
+
+func (window *Window) runQueuedCallbacks() {
+	for {
+		queuedCallbacksMutex.Lock()
+		callbacks := queuedCallbacks
+		queuedCallbacks = make([]func(), 0)
+		queuedCallbacksMutex.Unlock()
+
+		if len(callbacks) == 0 {
+			return
+		}
+
+		for _, callback := range callbacks {
+			callback()
+		}
+	}
 }
 
 func (window *Window) handleKeyInput(ev *tcell.EventKey) {
-	if ev.Key() == tcell.KeyRight { // Navigation Keys
-		if window.CursorMode == CursorModeBuffer {
-			// Get original cursor position
-			pos := window.CurrentBuffer.CursorPos
-
-			if ev.Modifiers()&tcell.ModCtrl != 0 {
-				// Move cursor to start of word
-				// Set variable to one character right of current position
-				endOfWord := pos + 1
-				if endOfWord >= len(window.CurrentBuffer.Contents) {
-					endOfWord = len(window.CurrentBuffer.Contents)
-				}
+	// Describe key instead of running it
+	if describeKeyPending {
+		describeKey(window, ev)
+		return
+	}
 
-				// Skip all spaces
-				for endOfWord < len(window.CurrentBuffer.Contents) && unicode.IsSpace(rune(window.CurrentBuffer.Contents[endOfWord])) {
-					endOfWord++
-				}
+	// Check key bindings
+	for _, keybinding := range Keybindings.Keybindings {
+		if keybinding.IsPressed(ev) && slices.Index(keybinding.GetCursorModes(), window.CursorMode) != -1 {
+			RunCommand(window, keybinding.Command)
+			return
+		}
+	}
 
-				// Find end of word
-				for endOfWord < len(window.CurrentBuffer.Contents) && !unicode.IsSpace(rune(window.CurrentBuffer.Contents[endOfWord])) {
-					endOfWord++
-				}
+	// Input bar
+	if window.CursorMode == CursorModeInputBar {
+		handleInputBarKey(window, ev)
+		return
+	}
 
-				window.SetCursorPos(endOfWord)
-			} else {
-				// Move cursor one character backwards
-				window.SetCursorPos(window.CurrentBuffer.CursorPos + 1)
+	// Command palette
+	if window.CursorMode == CursorModeCommandPalette {
+		handleCommandPaletteKey(window, ev)
+		return
+	}
+
+	// File finder
+	if window.CursorMode == CursorModeFileFinder {
+		handleFileFinderKey(window, ev)
+		return
+	}
+
+	// File browser
+	if window.CursorMode == CursorModeFileBrowser {
+		handleFileBrowserKey(window, ev)
+		return
+	}
+
+	// Dropdowns
+	if window.CursorMode == CursorModeDropdown {
+		handleDropdownKey(window, ev)
+		return
+	}
+
+	// Block selection
+	if window.CursorMode == CursorModeBuffer && (ev.Key() == tcell.KeyRight || ev.Key() == tcell.KeyLeft || ev.Key() == tcell.KeyUp || ev.Key() == tcell.KeyDown) {
+		if ev.Modifiers()&tcell.ModAlt != 0 && ev.Modifiers()&tcell.ModShift != 0 {
+			switch ev.Key() {
+			case tcell.KeyRight:
+				window.ExtendBlockSelection(1, 0)
+			case tcell.KeyLeft:
+				window.ExtendBlockSelection(-1, 0)
+			case tcell.KeyUp:
+				window.ExtendBlockSelection(0, -1)
+			case tcell.KeyDown:
+				window.ExtendBlockSelection(0, 1)
 			}
+			return
+		}
 
-			// Add to selection
-			if ev.Modifiers()&tcell.ModShift != 0 {
-				if window.CurrentBuffer.Selection == nil {
-					// Cancel cursor movement when creating selection without holding ctrl
-					if ev.Modifiers()&tcell.ModCtrl == 0 {
-						window.SetCursorPos(pos)
+		// Unset block selection
+		window.CurrentBuffer.BlockSelection = nil
+	}
+
+	// Navigation keys
+	if ev.Key() == tcell.KeyRight {
+		if window.CursorMode == CursorModeBuffer {
+			window.applyToCursors(func() {
+				// Get original cursor position
+				pos := window.CurrentBuffer.CursorPos
+
+				if ev.Modifiers()&tcell.ModCtrl != 0 {
+					// Move cursor to start of word
+					// Set variable to one character right of current position
+					endOfWord := pos + 1
+					if endOfWord >= len(window.CurrentBuffer.Contents) {
+						endOfWord = len(window.CurrentBuffer.Contents)
 					}
 
-					window.CurrentBuffer.Selection = &Selection{
-						selectionStart: pos,
-						selectionEnd:   window.CurrentBuffer.CursorPos,
+					// Skip all spaces
+					for endOfWord < len(window.CurrentBuffer.Contents) && unicode.IsSpace(rune(window.CurrentBuffer.Contents[endOfWord])) {
+						endOfWord++
 					}
+
+					// Find end of word
+					for endOfWord < len(window.CurrentBuffer.Contents) && !unicode.IsSpace(rune(window.CurrentBuffer.Contents[endOfWord])) {
+						endOfWord++
+					}
+
+					window.SetCursorPos(endOfWord)
 				} else {
-					window.CurrentBuffer.Selection.selectionEnd = window.CurrentBuffer.CursorPos
+					// Move cursor one character backwards
+					window.SetCursorPos(window.CurrentBuffer.CursorPos + 1)
 				}
-				// Prevent selecting dummy character at the end of the buffer
-				if window.CurrentBuffer.Selection.selectionEnd >= len(window.CurrentBuffer.Contents) {
-					window.CurrentBuffer.Selection.selectionEnd = len(window.CurrentBuffer.Contents) - 1
+
+				// Add to selection
+				if ev.Modifiers()&tcell.ModShift != 0 {
+					if window.CurrentBuffer.Selection == nil {
+						// Cancel cursor movement when creating selection without holding ctrl
+						if ev.Modifiers()&tcell.ModCtrl == 0 {
+							window.SetCursorPos(pos)
+						}
+
+						window.CurrentBuffer.Selection = &Selection{
+							selectionStart: pos,
+							selectionEnd:   window.CurrentBuffer.CursorPos,
+						}
+					} else {
+						window.CurrentBuffer.Selection.selectionEnd = window.CurrentBuffer.CursorPos
+					}
+					// Prevent selecting dummy character at the end of the buffer
+					if window.CurrentBuffer.Selection.selectionEnd >= len(window.CurrentBuffer.Contents) {
+						window.CurrentBuffer.Selection.selectionEnd = len(window.CurrentBuffer.Contents) - 1
+					}
+				} else if window.CurrentBuffer.Selection != nil {
+					// Unset selection
+					window.CurrentBuffer.Selection = nil
 				}
-			} else if window.CurrentBuffer.Selection != nil {
-				// Unset selection
-				window.CurrentBuffer.Selection = nil
-			}
+			})
 		}
 	} else if ev.Key() == tcell.KeyLeft {
 		if window.CursorMode == CursorModeBuffer {
-			// Get original cursor position
-			pos := window.CurrentBuffer.CursorPos
-
-			if ev.Modifiers()&tcell.ModCtrl != 0 {
-				// Move cursor to start of word
-				// Set variable to one character left of current position
-				startOfWord := pos - 1
-				if startOfWord < 0 {
-					startOfWord = 0
-				}
+			window.applyToCursors(func() {
+				// Get original cursor position
+				pos := window.CurrentBuffer.CursorPos
+
+				if ev.Modifiers()&tcell.ModCtrl != 0 {
+					// Move cursor to start of word
+					// Set variable to one character left of current position
+					startOfWord := pos - 1
+					if startOfWord < 0 {
+						startOfWord = 0
+					}
 
-				// Skip all spaces
-				for startOfWord >= 0 && len(window.CurrentBuffer.Contents) != 0 && unicode.IsSpace(rune(window.CurrentBuffer.Contents[startOfWord])) {
-					startOfWord--
-				}
+					// Skip all spaces
+					for startOfWord >= 0 && len(window.CurrentBuffer.Contents) != 0 && unicode.IsSpace(rune(window.CurrentBuffer.Contents[startOfWord])) {
+						startOfWord--
+					}
 
-				// Find start of word
-				for startOfWord >= 0 && len(window.CurrentBuffer.Contents) != 0 && !unicode.IsSpace(rune(window.CurrentBuffer.Contents[startOfWord])) {
-					startOfWord--
-				}
+					// Find start of word
+					for startOfWord >= 0 && len(window.CurrentBuffer.Contents) != 0 && !unicode.IsSpace(rune(window.CurrentBuffer.Contents[startOfWord])) {
+						startOfWord--
+					}
 
-				// Move one character to the right
-				startOfWord++
+					// Move one character to the right
+					startOfWord++
 
-				window.SetCursorPos(startOfWord)
-			} else {
-				// Move cursor one character backwards
-				window.SetCursorPos(window.CurrentBuffer.CursorPos - 1)
-			}
+					window.SetCursorPos(startOfWord)
+				} else {
+					// Move cursor one character backwards
+					window.SetCursorPos(window.CurrentBuffer.CursorPos - 1)
+				}
 
-			// Add to selection
-			if ev.Modifiers()&tcell.ModShift != 0 {
-				if window.CurrentBuffer.Selection == nil {
-					// Cancel cursor movement when creating selection without holding ctrl
-					if ev.Modifiers()&tcell.ModCtrl == 0 {
-						window.SetCursorPos(pos)
-					}
+				// Add to selection
+				if ev.Modifiers()&tcell.ModShift != 0 {
+					if window.CurrentBuffer.Selection == nil {
+						// Cancel cursor movement when creating selection without holding ctrl
+						if ev.Modifiers()&tcell.ModCtrl == 0 {
+							window.SetCursorPos(pos)
+						}
 
-					window.CurrentBuffer.Selection = &Selection{
-						selectionStart: pos,
-						selectionEnd:   window.CurrentBuffer.CursorPos,
+						window.CurrentBuffer.Selection = &Selection{
+							selectionStart: pos,
+							selectionEnd:   window.CurrentBuffer.CursorPos,
+						}
+						return
+					} else {
+						window.CurrentBuffer.Selection.selectionEnd = window.CurrentBuffer.CursorPos
 					}
+				} else if window.CurrentBuffer.Selection != nil {
+					// Unset selection
+					window.CurrentBuffer.Selection = nil
 					return
-				} else {
-					window.CurrentBuffer.Selection.selectionEnd = window.CurrentBuffer.CursorPos
 				}
-			} else if window.CurrentBuffer.Selection != nil {
-				// Unset selection
-				window.CurrentBuffer.Selection = nil
-				return
-			}
+			})
 		}
 	} else if ev.Key() == tcell.KeyUp {
 		if window.CursorMode == CursorModeBuffer {
-			// Get original cursor position
-			pos := window.CurrentBuffer.CursorPos
+			window.applyToCursors(func() {
+				// Get original cursor position
+				pos := window.CurrentBuffer.CursorPos
 
-			if ev.Modifiers()&tcell.ModCtrl != 0 {
-				// Move cursor to top of buffer
-				window.SetCursorPos(0)
-			} else {
-				// Move cursor one line up
-				x, y := window.GetCursorPos2D()
-				window.SetCursorPos2D(x, y-1)
-			}
+				if ev.Modifiers()&tcell.ModCtrl != 0 {
+					// Move cursor to top of buffer
+					window.SetCursorPos(0)
+				} else {
+					// Move cursor one line up
+					if window.SoftWrap {
+						window.MoveCursorVisualRow(-1)
+					} else {
+						x, y := window.GetCursorPos2D()
+						window.SetCursorPos2D(x, y-1)
+					}
+				}
 
-			// Add to selection
-			if ev.Modifiers()&tcell.ModShift != 0 {
 				// Add to selection
-				if window.CurrentBuffer.Selection == nil {
-					window.CurrentBuffer.Selection = &Selection{
-						selectionStart: pos,
-						selectionEnd:   window.CurrentBuffer.CursorPos,
+				if ev.Modifiers()&tcell.ModShift != 0 {
+					// Add to selection
+					if window.CurrentBuffer.Selection == nil {
+						window.CurrentBuffer.Selection = &Selection{
+							selectionStart: pos,
+							selectionEnd:   window.CurrentBuffer.CursorPos,
+						}
+					} else {
+						window.CurrentBuffer.Selection.selectionEnd = window.CurrentBuffer.CursorPos
 					}
-				} else {
-					window.CurrentBuffer.Selection.selectionEnd = window.CurrentBuffer.CursorPos
+				} else if window.CurrentBuffer.Selection != nil {
+					// Unset selection
+					window.CurrentBuffer.Selection = nil
+					return
 				}
-			} else if window.CurrentBuffer.Selection != nil {
-				// Unset selection
-				window.CurrentBuffer.Selection = nil
-				return
-			}
-		} else if window.CursorMode == CursorModeDropdown {
-			dropdown := ActiveDropdown
-			dropdown.Selected--
-			if dropdown.Selected < 0 {
-				dropdown.Selected = 0
-			}
-		} else if window.CursorMode == CursorModeInputBar {
-			if len(inputHistory) == 0 {
-				return
-			}
-
-			current := slices.Index(inputHistory, currentInputRequest.input)
-			if current < 0 {
-				current = len(inputHistory) - 1
-			} else if current != 0 {
-				current--
-			}
-
-			currentInputRequest.input = inputHistory[current]
-			currentInputRequest.cursorPos = len(inputHistory[current])
+			})
 		}
 	} else if ev.Key() == tcell.KeyDown {
 		if window.CursorMode == CursorModeBuffer {
-			// Get original cursor position
-			pos := window.CurrentBuffer.CursorPos
+			window.applyToCursors(func() {
+				// Get original cursor position
+				pos := window.CurrentBuffer.CursorPos
 
-			if ev.Modifiers()&tcell.ModCtrl != 0 {
-				// Move cursor to bottom of buffer
-				window.SetCursorPos(len(window.CurrentBuffer.Contents))
-			} else {
-				// Move cursor one line down
-				x, y := window.GetCursorPos2D()
-				window.SetCursorPos2D(x, y+1)
-			}
+				if ev.Modifiers()&tcell.ModCtrl != 0 {
+					// Move cursor to bottom of buffer
+					window.SetCursorPos(len(window.CurrentBuffer.Contents))
+				} else {
+					// Move cursor one line down
+					if window.SoftWrap {
+						window.MoveCursorVisualRow(1)
+					} else {
+						x, y := window.GetCursorPos2D()
+						window.SetCursorPos2D(x, y+1)
+					}
+				}
 
-			// Add to selection
-			if ev.Modifiers()&tcell.ModShift != 0 {
 				// Add to selection
-				if window.CurrentBuffer.Selection == nil {
-					window.CurrentBuffer.Selection = &Selection{
-						selectionStart: pos,
-						selectionEnd:   window.CurrentBuffer.CursorPos,
+				if ev.Modifiers()&tcell.ModShift != 0 {
+					// Add to selection
+					if window.CurrentBuffer.Selection == nil {
+						window.CurrentBuffer.Selection = &Selection{
+							selectionStart: pos,
+							selectionEnd:   window.CurrentBuffer.CursorPos,
+						}
+					} else {
+						window.CurrentBuffer.Selection.selectionEnd = window.CurrentBuffer.CursorPos
 					}
-				} else {
-					window.CurrentBuffer.Selection.selectionEnd = window.CurrentBuffer.CursorPos
-				}
-				// Prevent selecting dummy character at the end of the buffer
-				if window.CurrentBuffer.Selection.selectionEnd >= len(window.CurrentBuffer.Contents) {
-					window.CurrentBuffer.Selection.selectionEnd = len(window.CurrentBuffer.Contents) - 1
+					// Prevent selecting dummy character at the end of the buffer
+					if window.CurrentBuffer.Selection.selectionEnd >= len(window.CurrentBuffer.Contents) {
+						window.CurrentBuffer.Selection.selectionEnd = len(window.CurrentBuffer.Contents) - 1
+					}
+				} else if window.CurrentBuffer.Selection != nil {
+					// Unset selection
+					window.CurrentBuffer.Selection = nil
+					return
 				}
-			} else if window.CurrentBuffer.Selection != nil {
-				// Unset selection
-				window.CurrentBuffer.Selection = nil
-				return
-			}
-		} else if window.CursorMode == CursorModeDropdown {
-			dropdown := ActiveDropdown
-			dropdown.Selected++
-			if dropdown.Selected >= len(dropdown.Options) {
-				dropdown.Selected = len(dropdown.Options) - 1
-			}
-		} else if window.CursorMode == CursorModeInputBar {
-			if len(inputHistory) == 0 {
-				return
-			}
+			})
+		}
+	} else if ev.Key() == tcell.KeyEscape {
+		if window.CursorMode == CursorModeBuffer {
+			// Dismiss error messages
+			DismissMessages()
 
-			current := slices.Index(inputHistory, currentInputRequest.input)
-			if current < 0 {
-				return
-			} else if current == len(inputHistory)-1 {
-				currentInputRequest.input = ""
-				return
-			} else {
-				current++
+			// Cancel search writing to the buffer
+			if currentGrepSearch != nil && currentGrepSearch.buffer == window.CurrentBuffer && CancelGrep() {
+				PrintMessage(window, "Search cancelled.")
 			}
 
-			currentInputRequest.input = inputHistory[current]
-			currentInputRequest.cursorPos = len(inputHistory[current])
-		}
-	} else if ev.Key() == tcell.KeyEscape {
-		if window.CursorMode == CursorModeInputBar {
-			currentInputRequest.inputChannel <- ""
-			currentInputRequest = nil
-			window.CursorMode = CursorModeBuffer
+			// Collapse multiple cursors back into one
+			window.CurrentBuffer.CollapseCursors()
+
+			// Unset block selection
+			window.CurrentBuffer.BlockSelection = nil
 		} else {
 			ClearDropdowns()
 			window.CursorMode = CursorModeBuffer
 		}
 	}
 
-	// Check key bindings
-	for _, keybinding := range Keybindings.Keybindings {
-		if keybinding.IsPressed(ev) && slices.Index(keybinding.GetCursorModes(), window.CursorMode) != -1 {
-			RunCommand(window, keybinding.Command)
-			return
+	// Read-only buffers can only open locations
+	if window.CursorMode == CursorModeBuffer && window.CurrentBuffer.readOnly {
+		if ev.Key() == tcell.KeyEnter {
+			window.OpenLocationAtCursor()
 		}
+		return
 	}
 
 	// Typing
 	if ev.Key() == tcell.KeyBackspace2 {
-		if window.CursorMode == CursorModeBuffer {
-			str := window.CurrentBuffer.Contents
-			index := window.CurrentBuffer.CursorPos
-
-			if window.CurrentBuffer.Selection != nil {
-				edge1, edge2 := window.CurrentBuffer.GetSelectionEdges()
-				if edge2 == len(window.CurrentBuffer.Contents) {
-					edge2 = len(window.CurrentBuffer.Contents) - 1
+		if window.CursorMode == CursorModeBuffer && window.CurrentBuffer.BlockSelection != nil {
+			window.DeleteBlockText(true)
+		} else if window.CursorMode == CursorModeBuffer {
+			window.applyToCursors(func() {
+				// Remove both characters of an empty pair
+				if window.deleteAutoPair() {
+					return
 				}
 
-				str = str[:edge1] + str[edge2+1:]
-				window.CurrentBuffer.Contents = str
-				window.SetCursorPos(edge1)
-				window.CurrentBuffer.Selection = nil
-			} else if index != 0 {
-				str = str[:index-1] + str[index:]
-				window.CurrentBuffer.Contents = str
-				window.SetCursorPos(window.CurrentBuffer.CursorPos - 1)
-			}
-		} else if window.CursorMode == CursorModeInputBar {
-			str := currentInputRequest.input
-			index := currentInputRequest.cursorPos
-
-			if index != 0 {
-				str = str[:index-1] + str[index:]
-				currentInputRequest.cursorPos--
-				currentInputRequest.input = str
-			}
+				str := window.CurrentBuffer.Contents
+				index := window.CurrentBuffer.CursorPos
+
+				if window.CurrentBuffer.Selection != nil {
+					edge1, edge2 := window.CurrentBuffer.GetSelectionEdges()
+					if edge2 == len(window.CurrentBuffer.Contents) {
+						edge2 = len(window.CurrentBuffer.Contents) - 1
+					}
+
+					str = str[:edge1] + str[edge2+1:]
+					window.CurrentBuffer.SetContents(str)
+					window.SetCursorPos(edge1)
+					window.CurrentBuffer.Selection = nil
+				} else if index != 0 {
+					str = str[:index-1] + str[index:]
+					window.CurrentBuffer.SetContents(str)
+					window.SetCursorPos(window.CurrentBuffer.CursorPos - 1)
+				}
+			})
 		}
 	} else if ev.Key() == tcell.KeyTab {
-		if window.CursorMode == CursorModeBuffer {
-			str := window.CurrentBuffer.Contents
+		if window.CursorMode == CursorModeBuffer && window.CurrentBuffer.BlockSelection != nil {
+			window.InsertBlockText("\t")
+		} else if window.CursorMode == CursorModeBuffer {
+			window.applyToCursors(func() {
+				str := window.CurrentBuffer.Contents
 
-			// Remove selected text
-			if window.CurrentBuffer.Selection != nil {
-				edge1, edge2 := window.CurrentBuffer.GetSelectionEdges()
-				if edge2 == len(window.CurrentBuffer.Contents) {
-					edge2 = len(window.CurrentBuffer.Contents) - 1
-				}
+				// Remove selected text
+				if window.CurrentBuffer.Selection != nil {
+					edge1, edge2 := window.CurrentBuffer.GetSelectionEdges()
+					if edge2 == len(window.CurrentBuffer.Contents) {
+						edge2 = len(window.CurrentBuffer.Contents) - 1
+					}
 
-				str = str[:edge1] + str[edge2+1:]
-				window.CurrentBuffer.Contents = str
-				window.SetCursorPos(edge1)
-				window.CurrentBuffer.Selection = nil
-			}
+					str = str[:edge1] + str[edge2+1:]
+					window.CurrentBuffer.SetContents(str)
+					window.SetCursorPos(edge1)
+					window.CurrentBuffer.Selection = nil
+				}
 
-			index := window.CurrentBuffer.CursorPos
+				index := window.CurrentBuffer.CursorPos
 
-			if index == len(str) {
-				str += "\t"
-			} else {
-				str = str[:index] + "\t" + str[index:]
-			}
-			window.CurrentBuffer.Contents = str
-			window.SetCursorPos(window.CurrentBuffer.CursorPos + 1)
+				if index == len(str) {
+					str += "\t"
+				} else {
+					str = str[:index] + "\t" + str[index:]
+				}
+				window.CurrentBuffer.SetContents(str)
+				window.SetCursorPos(window.CurrentBuffer.CursorPos + 1)
+			})
 		}
 	} else if ev.Key() == tcell.KeyEnter {
 		if window.CursorMode == CursorModeBuffer {
-			str := window.CurrentBuffer.Contents
+			// Unset block selection
+			window.CurrentBuffer.BlockSelection = nil
 
-			// Remove selected text
-			if window.CurrentBuffer.Selection != nil {
-				edge1, edge2 := window.CurrentBuffer.GetSelectionEdges()
-				if edge2 == len(window.CurrentBuffer.Contents) {
-					edge2 = len(window.CurrentBuffer.Contents) - 1
-				}
+			window.applyToCursors(func() {
+				str := window.CurrentBuffer.Contents
 
-				str = str[:edge1] + str[edge2+1:]
-				window.CurrentBuffer.Contents = str
-				window.SetCursorPos(edge1)
-				window.CurrentBuffer.Selection = nil
-			}
+				// Remove selected text
+				if window.CurrentBuffer.Selection != nil {
+					edge1, edge2 := window.CurrentBuffer.GetSelectionEdges()
+					if edge2 == len(window.CurrentBuffer.Contents) {
+						edge2 = len(window.CurrentBuffer.Contents) - 1
+					}
 
-			index := window.CurrentBuffer.CursorPos
+					str = str[:edge1] + str[edge2+1:]
+					window.CurrentBuffer.SetContents(str)
+					window.SetCursorPos(edge1)
+					window.CurrentBuffer.Selection = nil
+				}
 
-			if index == len(str) {
-				str += "\n"
-			} else {
-				str = str[:index] + "\n" + str[index:]
-			}
-			window.CurrentBuffer.Contents = str
-			window.SetCursorPos(window.CurrentBuffer.CursorPos + 1)
-		} else if window.CursorMode == CursorModeInputBar {
-			if currentInputRequest.input == "" && slices.Index(inputHistory, currentInputRequest.input) == -1 {
-				inputHistory = append(inputHistory, currentInputRequest.input)
-			}
-			currentInputRequest.inputChannel <- currentInputRequest.input
-			currentInputRequest = nil
-			window.CursorMode = CursorModeBuffer
-		} else if window.CursorMode == CursorModeDropdown {
-			d := ActiveDropdown
-			d.Action(d.Selected)
+				index := window.CurrentBuffer.CursorPos
+
+				if index == len(str) {
+					str += "\n"
+				} else {
+					str = str[:index] + "\n" + str[index:]
+				}
+				window.CurrentBuffer.SetContents(str)
+				window.SetCursorPos(window.CurrentBuffer.CursorPos + 1)
+			})
 		}
 	} else if ev.Key() == tcell.KeyRune {
-		if window.CursorMode == CursorModeBuffer {
-			str := window.CurrentBuffer.Contents
-
-			// Remove selected text
-			if window.CurrentBuffer.Selection != nil {
-				edge1, edge2 := window.CurrentBuffer.GetSelectionEdges()
-				if edge2 == len(window.CurrentBuffer.Contents) {
-					edge2 = len(window.CurrentBuffer.Contents) - 1
+		if window.CursorMode == CursorModeBuffer && window.CurrentBuffer.BlockSelection != nil {
+			window.InsertBlockText(string(ev.Rune()))
+		} else if window.CursorMode == CursorModeBuffer {
+			window.applyToCursors(func() {
+				// Close brackets and quotes automatically
+				if window.insertAutoPair(ev.Rune()) {
+					return
 				}
 
-				str = str[:edge1] + str[edge2+1:]
-				window.CurrentBuffer.Contents = str
-				window.SetCursorPos(edge1)
-				window.CurrentBuffer.Selection = nil
-			}
+				str := window.CurrentBuffer.Contents
+
+				// Remove selected text
+				if window.CurrentBuffer.Selection != nil {
+					edge1, edge2 := window.CurrentBuffer.GetSelectionEdges()
+					if edge2 == len(window.CurrentBuffer.Contents) {
+						edge2 = len(window.CurrentBuffer.Contents) - 1
+					}
 
-			index := window.CurrentBuffer.CursorPos
+					str = str[:edge1] + str[edge2+1:]
+					window.CurrentBuffer.SetContents(str)
+					window.SetCursorPos(edge1)
+					window.CurrentBuffer.Selection = nil
+				}
 
-			if index == len(str) {
-				str += string(ev.Rune())
-			} else {
-				str = str[:index] + string(ev.Rune()) + str[index:]
-			}
-			window.CurrentBuffer.Contents = str
-			window.SetCursorPos(window.CurrentBuffer.CursorPos + 1)
-		} else if window.CursorMode == CursorModeInputBar {
-			str := currentInputRequest.input
-			index := currentInputRequest.cursorPos
-
-			if index == len(str) {
-				str += string(ev.Rune())
-			} else {
-				str = str[:index] + string(ev.Rune()) + str[index:]
-			}
+				index := window.CurrentBuffer.CursorPos
 
-			currentInputRequest.cursorPos++
-			currentInputRequest.input = str
+				if index == len(str) {
+					str += string(ev.Rune())
+				} else {
+					str = str[:index] + string(ev.Rune()) + str[index:]
+				}
+				window.CurrentBuffer.SetContents(str)
+				window.SetCursorPos(window.CurrentBuffer.CursorPos + 1)
+			})
 		}
 	}
 }
@@ -528,14 +690,148 @@ func (window *Window) handleKeyInput(ev *tcell.EventKey) {
 func (window *Window) handleMouseInput(ev *tcell.EventMouse) {
 	mouseX, mouseY := ev.Position()
 
-	// Left click was pressed
-	if ev.Buttons() == tcell.Button1 {
+	// Hover or click in dropdowns
+	if !dragSelecting && handleDropdownMouse(window, ev) {
+		return
+	}
+
+	// Click in top menu
+	if window.ShowTopMenu && mouseY == 0 && ev.Buttons() == tcell.Button1 && !dragSelecting {
+		if !mouseHeld {
+			handleTopMenuClick(window, mouseX)
+		}
+		mouseHeld = true
+		return
+	}
+
+	// Close dropdowns when clicking anywhere else
+	if window.CursorMode == CursorModeDropdown && ev.Buttons() == tcell.Button1 && !mouseHeld {
+		ClearDropdowns()
+		window.CursorMode = CursorModeBuffer
+		mouseHeld = true
+		return
+	}
+
+	// Click or scroll in tab bar
+	if window.ShowTabBar && mouseY == getTabBarY(window) && ev.Buttons() != tcell.ButtonNone && !dragSelecting {
+		if !mouseHeld {
+			handleTabBarMouse(window, ev)
+		}
+		if ev.Buttons()&(tcell.WheelUp|tcell.WheelDown|tcell.WheelLeft|tcell.WheelRight) == 0 {
+			mouseHeld = true
+		}
+		return
+	}
+
+	// Scroll with mouse wheel
+	if ev.Buttons()&(tcell.WheelUp|tcell.WheelDown|tcell.WheelLeft|tcell.WheelRight) != 0 {
+		deltaX, deltaY := 0, 0
+		switch {
+		case ev.Buttons()&tcell.WheelUp != 0:
+			deltaY = -Config.ScrollSpeed
+		case ev.Buttons()&tcell.WheelDown != 0:
+			deltaY = Config.ScrollSpeed
+		case ev.Buttons()&tcell.WheelLeft != 0:
+			deltaX = -Config.ScrollSpeed
+		case ev.Buttons()&tcell.WheelRight != 0:
+			deltaX = Config.ScrollSpeed
+		}
+
+		// Scroll horizontally while holding shift
+		if ev.Modifiers()&tcell.ModShift != 0 {
+			deltaX, deltaY = deltaY, deltaX
+		}
+
+		if x1, y1, x2, y2 := getFileBrowserDimensions(window); window.ShowFileBrowser && mouseX >= x1 && mouseY >= y1 && mouseX <= x2 && mouseY <= y2 {
+			fileBrowser.moveSelection(deltaY)
+		} else {
+			window.ScrollBuffer(deltaX, deltaY)
+		}
+		return
+	}
+
+	// Click or drag scrollbar and minimap
+	if ev.Buttons() == tcell.Button1 && !dragSelecting {
+		if handleScrollbarMouse(window, mouseX, mouseY) || handleMinimapMouse(window, mouseX, mouseY) {
+			mouseHeld = true
+			return
+		}
+	}
+
+	// Click in file browser
+	if ev.Buttons() == tcell.Button1 && window.ShowFileBrowser && !dragSelecting {
+		x1, y1, x2, y2 := getFileBrowserDimensions(window)
+		if mouseX >= x1 && mouseY >= y1 && mouseX <= x2 && mouseY <= y2 {
+			if !mouseHeld {
+				handleFileBrowserClick(window, mouseX, mouseY)
+			}
+			mouseHeld = true
+			return
+		}
+	}
+
+	// Left click was pressed while holding alt
+	if ev.Buttons() == tcell.Button1 && ev.Modifiers()&tcell.ModAlt != 0 {
+		x1, y1, x2, y2 := window.GetTextAreaDimensions()
+		if mouseX >= x1 && mouseY >= y1 && mouseX <= x2 && mouseY <= y2 {
+			// Get line and display column under mouse
+			line := mouseY - y1 + window.CurrentBuffer.OffsetY
+			col := mouseX - x1 + window.CurrentBuffer.OffsetX
+			if window.UsesVisualRows() {
+				x, y := window.AbsolutePosToCursorPos2D(mouseX, mouseY)
+				line = y
+				col = window.CurrentBuffer.PosToDisplayColumn(window.CursorPos2DToCursorPos(x, y))
+			}
+
+			if !mouseHeld {
+				altClickLine, altClickCol = line, col
+			} else if window.CurrentBuffer.BlockSelection != nil {
+				// Extend block selection
+				window.SetBlockSelectionCursor(line, col)
+			} else if line != altClickLine || col != altClickCol {
+				// Start block selection
+				window.StartBlockSelection(altClickLine, altClickCol)
+				window.SetBlockSelectionCursor(line, col)
+			}
+		}
+		mouseHeld = true
+	} else if ev.Buttons() == tcell.Button1 && ev.Modifiers()&tcell.ModShift != 0 {
+		// Ensure click was in buffer area
+		x1, y1, x2, y2 := window.GetTextAreaDimensions()
+		if mouseX >= x1 && mouseY >= y1 && mouseX <= x2 && mouseY <= y2 {
+			pos := window.CursorPos2DToCursorPos(window.AbsolutePosToCursorPos2D(mouseX, mouseY))
+
+			// Extend selection from cursor
+			if window.CurrentBuffer.Selection == nil {
+				window.CurrentBuffer.Selection = &Selection{
+					selectionStart: window.CurrentBuffer.CursorPos,
+					selectionEnd:   pos,
+				}
+			} else {
+				window.CurrentBuffer.Selection.selectionEnd = pos
+			}
+			// Prevent selecting dummy character at the end of the buffer
+			if window.CurrentBuffer.Selection.selectionEnd >= len(window.CurrentBuffer.Contents) {
+				window.CurrentBuffer.Selection.selectionEnd = len(window.CurrentBuffer.Contents) - 1
+			}
+
+			window.CurrentBuffer.CollapseCursors()
+			window.CurrentBuffer.BlockSelection = nil
+			window.SetCursorPos(pos)
+		}
+		mouseHeld = true
+	} else if ev.Buttons() == tcell.Button1 {
+		// Ignore drags that started outside of the text area
+		if mouseHeld && !dragSelecting {
+			return
+		}
+
 		// Get last click time
 		lastClickTime := time.UnixMilli(lastClick)
 		// Ensure click was in buffer area
 		x1, y1, x2, y2 := window.GetTextAreaDimensions()
 		if mouseX >= x1 && mouseY >= y1 && mouseX <= x2 && mouseY <= y2 {
-			currentX, currentY := window.GetCursorPos2D()
+			dragSelecting = true
 			bufferMouseX, bufferMouseY := window.AbsolutePosToCursorPos2D(mouseX, mouseY)
 			if mouseHeld {
 				// Add to selection
@@ -556,68 +852,15 @@ func (window *Window) handleMouseInput(ev *tcell.EventMouse) {
 				if window.CurrentBuffer.Selection.selectionEnd >= len(window.CurrentBuffer.Contents) {
 					window.CurrentBuffer.Selection.selectionEnd = len(window.CurrentBuffer.Contents) - 1
 				}
-			} else if currentX == bufferMouseX && currentY == bufferMouseY && window.CurrentBuffer.CursorPos < len(window.CurrentBuffer.Contents) && time.Since(lastClickTime).Milliseconds() < 300 {
-				selectedText := window.CurrentBuffer.GetSelectedText()
-				if window.CurrentBuffer.Selection == nil || strings.HasSuffix(selectedText, "\n") {
-					// Select word
-					startOfWord := window.CurrentBuffer.CursorPos
-					endOfWord := window.CurrentBuffer.CursorPos
+			} else if window.CursorPos2DToCursorPos(bufferMouseX, bufferMouseY) == lastClickPos && lastClickPos < len(window.CurrentBuffer.Contents) && time.Since(lastClickTime).Milliseconds() < 300 {
+				clickCount++
 
-					// Find end of word
-					for i := window.CurrentBuffer.CursorPos + 1; i < len(window.CurrentBuffer.Contents); i++ {
-						currentRune := rune(window.CurrentBuffer.Contents[i])
-						if unicode.IsLetter(currentRune) || unicode.IsDigit(currentRune) || currentRune == '_' {
-							endOfWord++
-						} else {
-							break
-						}
-					}
-
-					// Find start of word
-					for i := window.CurrentBuffer.CursorPos - 1; i >= 0; i-- {
-						currentRune := rune(window.CurrentBuffer.Contents[i])
-						if unicode.IsLetter(currentRune) || unicode.IsDigit(currentRune) || currentRune == '_' {
-							startOfWord--
-						} else {
-							break
-						}
-					}
-
-					// Add to selection
-					window.CurrentBuffer.Selection = &Selection{
-						selectionStart: startOfWord,
-						selectionEnd:   endOfWord,
-					}
+				if clickCount%2 == 0 {
+					// Select word on double click
+					window.SelectWord()
 				} else {
-					// Select line
-					startOfLine := window.CurrentBuffer.CursorPos
-					endOfLine := window.CurrentBuffer.CursorPos
-
-					// Find end of line
-					for i := window.CurrentBuffer.CursorPos + 1; i < len(window.CurrentBuffer.Contents); i++ {
-						currentLetter := window.CurrentBuffer.Contents[i]
-
-						endOfLine++
-						if currentLetter == '\n' {
-							break
-						}
-					}
-
-					// Find start of line
-					for i := window.CurrentBuffer.CursorPos - 1; i >= 0; i-- {
-						currentLetter := window.CurrentBuffer.Contents[i]
-						if currentLetter != '\n' {
-							startOfLine--
-						} else {
-							break
-						}
-					}
-
-					// Add to selection
-					window.CurrentBuffer.Selection = &Selection{
-						selectionStart: startOfLine,
-						selectionEnd:   endOfLine,
-					}
+					// Select line on triple click
+					window.SelectLine()
 				}
 
 				// Set last click time
@@ -625,23 +868,155 @@ func (window *Window) handleMouseInput(ev *tcell.EventMouse) {
 
 				return
 			} else {
+				// Focus buffer
+				if window.CursorMode == CursorModeFileBrowser {
+					window.CursorMode = CursorModeBuffer
+				}
+
 				// Clear selection
 				if window.CurrentBuffer.Selection != nil {
 					window.CurrentBuffer.Selection = nil
 				}
+
+				// Collapse multiple cursors
+				window.CurrentBuffer.CollapseCursors()
+
+				// Unset block selection
+				window.CurrentBuffer.BlockSelection = nil
 			}
 			// Move cursor
 			window.SetCursorPos2D(bufferMouseX, bufferMouseY)
 
-			// Set last click time
+			// Set last click time and position
 			lastClick = time.Now().UnixMilli()
+			lastClickPos = window.CurrentBuffer.CursorPos
+			clickCount = 1
+		} else if mouseHeld {
+			// Scroll while dragging past the edges of the text area
+			dragMouseX, dragMouseY = mouseX, mouseY
+			window.autoscrollDragSelection()
+		} else if window.ShowLineIndex && Config.ShowFoldMarkers && !mouseHeld && mouseX == x1-getFoldColumnWidth() && mouseY >= y1 && mouseY <= y2 {
+			// Fold or unfold when clicking fold markers
+			_, line := window.AbsolutePosToCursorPos2D(x1, mouseY)
+			window.ToggleFold(line)
+		} else if window.ShowLineIndex && mouseX >= x1-getLineIndexSize(window) && mouseX < x1 && mouseY >= y1 && mouseY <= y2 {
+			// Select line when clicking line index
+			_, line := window.AbsolutePosToCursorPos2D(x1, mouseY)
+			window.CurrentBuffer.Selection = nil
+			window.CurrentBuffer.CollapseCursors()
+			window.CurrentBuffer.BlockSelection = nil
+			window.SetCursorPos2D(0, line)
+			window.SelectLine()
+			dragSelecting = true
 		}
 		mouseHeld = true
 	} else if ev.Buttons() == tcell.ButtonNone {
+		if mouseHeld && altClickLine >= 0 && window.CurrentBuffer.BlockSelection == nil {
+			// Add or remove cursor
+			pos, _ := window.CurrentBuffer.DisplayColumnToPos(altClickLine, altClickCol)
+			if !window.CurrentBuffer.RemoveCursorAt(pos) && pos != window.CurrentBuffer.CursorPos {
+				window.CurrentBuffer.AddCursor(pos, nil)
+			}
+		}
+
 		if mouseHeld {
 			mouseHeld = false
+			dragSelecting = false
+			scrollbarDragging = false
+			minimapDragging = false
+			altClickLine, altClickCol = -1, -1
+		}
+	}
+}
+
+// ScrollBuffer moves the view of the current buffer without moving the cursor
+func (window *Window) ScrollBuffer(deltaX, deltaY int) {
+	buffer := window.CurrentBuffer
+
+	lines := strings.Split(buffer.Contents, "\n")
+	rows := len(lines)
+	if window.UsesVisualRows() {
+		rows = len(window.GetVisualRows())
+	}
+	if window.SoftWrap {
+		deltaX = 0
+	}
+
+	width := 0
+	for _, line := range lines {
+		width = max(width, len(line))
+	}
+
+	buffer.OffsetY = min(max(buffer.OffsetY+deltaY, 0), max(rows-1, 0))
+	buffer.OffsetX = min(max(buffer.OffsetX+deltaX, 0), width)
+}
+
+// dragSelectionStep scrolls towards the mouse if it is outside of the text area and extends the selection to it.
+// It returns false if the view could not be scrolled
+func (window *Window) dragSelectionStep() bool {
+	buffer := window.CurrentBuffer
+	if len(buffer.Contents) == 0 {
+		return false
+	}
+	x1, y1, x2, y2 := window.GetTextAreaDimensions()
+
+	deltaX, deltaY := 0, 0
+	if dragMouseY < y1 {
+		deltaY = -1
+	} else if dragMouseY > y2 {
+		deltaY = 1
+	}
+	if dragMouseX < x1 {
+		deltaX = -1
+	} else if dragMouseX > x2 {
+		deltaX = 1
+	}
+
+	offsetX, offsetY := buffer.OffsetX, buffer.OffsetY
+	window.ScrollBuffer(deltaX, deltaY)
+	scrolled := offsetX != buffer.OffsetX || offsetY != buffer.OffsetY
+
+	// Extend selection to the edge of the text area
+	pos := window.CursorPos2DToCursorPos(window.AbsolutePosToCursorPos2D(min(max(dragMouseX, x1), x2), min(max(dragMouseY, y1), y2)))
+	if buffer.Selection == nil {
+		buffer.Selection = &Selection{
+			selectionStart: buffer.CursorPos,
+			selectionEnd:   pos,
 		}
+	} else {
+		buffer.Selection.selectionEnd = pos
 	}
+	// Prevent selecting dummy character at the end of the buffer
+	if buffer.Selection.selectionEnd >= len(buffer.Contents) {
+		buffer.Selection.selectionEnd = len(buffer.Contents) - 1
+	}
+	window.SetCursorPos(pos)
+
+	return scrolled
+}
+
+// autoscrollDragSelection keeps scrolling while a selection is dragged past the edges of the text area
+func (window *Window) autoscrollDragSelection() {
+	if !window.dragSelectionStep() || dragScrolling {
+		return
+	}
+	dragScrolling = true
+
+	var step func()
+	step = func() {
+		if !dragSelecting || window.closed || !window.dragSelectionStep() {
+			dragScrolling = false
+			return
+		}
+
+		time.AfterFunc(50*time.Millisecond, func() {
+			window.RunOnMainLoop(step)
+		})
+	}
+
+	time.AfterFunc(50*time.Millisecond, func() {
+		window.RunOnMainLoop(step)
+	})
 }
 
 func (window *Window) Close() {
@@ -660,10 +1035,30 @@ func (window *Window) GetTextAreaDimensions() (int, int, int, int) {
 		y1++
 	}
 
+	if window.ShowTabBar {
+		y1++
+	}
+
+	if window.ShowFileBrowser {
+		x1 += getFileBrowserWidth(window)
+	}
+
 	if window.ShowLineIndex {
 		x1 += getLineIndexSize(window)
 	}
 
+	if window.ShowScrollbar {
+		x2--
+	}
+
+	if window.ShowMinimap {
+		x2 -= getMinimapWidth(window)
+	}
+
+	if window.ShowStatusLine {
+		y2--
+	}
+
 	return x1, y1, x2 - 1, y2 - 2
 }
 
@@ -728,6 +1123,17 @@ func (window *Window) AbsolutePosToCursorPos2D(x, y int) (int, int) {
 		y = 0
 	}
 
+	// Map position onto wrapped or folded rows
+	if window.UsesVisualRows() {
+		rows := window.GetVisualRows()
+		if y >= len(rows) {
+			y = len(rows) - 1
+		}
+
+		pos := window.visualColumnToPos(rows, y, x)
+		return pos - window.CurrentBuffer.GetLineOffsets()[rows[y].Line], rows[y].Line
+	}
+
 	split := strings.SplitAfter(window.CurrentBuffer.Contents+" ", "\n")
 
 	if y >= len(split) {
@@ -768,10 +1174,14 @@ func (window *Window) GetAbsoluteCursorPos() (int, int) {
 }
 
 func (window *Window) GetCursorPos2D() (int, int) {
+	return window.CursorPosToCursorPos2D(window.CurrentBuffer.CursorPos)
+}
+
+func (window *Window) CursorPosToCursorPos2D(pos int) (int, int) {
 	cursorX := 0
 	cursorY := 0
 
-	for i := 0; i < window.CurrentBuffer.CursorPos; i++ {
+	for i := 0; i < pos && i < len(window.CurrentBuffer.Contents); i++ {
 		char := window.CurrentBuffer.Contents[i]
 		if char == '\n' {
 			cursorY++
@@ -795,6 +1205,12 @@ func (window *Window) SetCursorPos(position int) {
 		window.CurrentBuffer.CursorPos = len(window.CurrentBuffer.Contents)
 	}
 
+	// Open folds hiding the cursor
+	if window.CurrentBuffer.HasClosedFolds() {
+		_, line := window.GetCursorPos2D()
+		window.CurrentBuffer.Unfold(line, false)
+	}
+
 	window.SyncBufferOffset()
 }
 
@@ -839,6 +1255,24 @@ func (window *Window) SetCursorPos2D(x, y int) {
 
 	// Limit x and y
 	y = min(y, len(lines)-1)
+
+	// Skip lines hidden by folds in the direction the cursor moves
+	if hiddenLines := window.CurrentBuffer.GetHiddenLines(); y < len(hiddenLines) && hiddenLines[y] {
+		_, cursorY := window.GetCursorPos2D()
+
+		target := y
+		if y > cursorY {
+			for target < len(hiddenLines) && hiddenLines[target] {
+				target++
+			}
+		}
+		if target <= cursorY || target >= len(hiddenLines) {
+			for target = y; target > 0 && hiddenLines[target]; target-- {
+			}
+		}
+		y = target
+	}
+
 	x = min(x, len(lines[y].str)-1)
 
 	window.SetCursorPos(lines[y].charIndex + x)
@@ -848,6 +1282,14 @@ func (window *Window) SyncBufferOffset() {
 	x, y := window.GetCursorPos2D()
 	bufferX1, bufferY1, bufferX2, bufferY2 := window.GetTextAreaDimensions()
 
+	// Scroll by visual rows when wrapping or folding lines
+	if window.UsesVisualRows() {
+		x, y = window.GetCursorVisualPos()
+	}
+	if window.SoftWrap {
+		window.CurrentBuffer.OffsetX = 0
+	}
+
 	if y < window.CurrentBuffer.OffsetY {
 		window.CurrentBuffer.OffsetY = y
 	} else if y > window.CurrentBuffer.OffsetY+(bufferY2-bufferY1) {
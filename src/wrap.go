@@ -0,0 +1,163 @@
+package main
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+type VisualRow struct {
+	Line       int
+	Start, End int
+	First      bool
+}
+
+// visualRowsKey holds everything visual rows depend on, so they are only split again when one of them changes
+type visualRowsKey struct {
+	revision     int
+	foldsVersion int
+	width        int
+	wrapWords    bool
+}
+
+// UsesVisualRows returns whether rows of the text area differ from lines, because lines are wrapped or folded
+func (window *Window) UsesVisualRows() bool {
+	return window.SoftWrap || window.CurrentBuffer.HasClosedFolds()
+}
+
+// GetVisualRows splits the lines of the current buffer into rows that fit the text area width if soft wrap is enabled.
+// Lines hidden by folds are left out. The rows are cached and must not be modified
+func (window *Window) GetVisualRows() []VisualRow {
+	buffer := window.CurrentBuffer
+
+	// Leave one column free for the cursor at the end of a line
+	x1, _, x2, _ := window.GetTextAreaDimensions()
+	width := max(x2-x1, 1)
+	if !window.SoftWrap {
+		width = math.MaxInt
+	}
+
+	hiddenLines := buffer.GetHiddenLines()
+
+	key := visualRowsKey{revision: buffer.revision, foldsVersion: buffer.foldsVersion, width: width, wrapWords: Config.SoftWrapWords}
+	if buffer.visualRows != nil && buffer.visualRowsKey == key {
+		return buffer.visualRows
+	}
+
+	rows := make([]VisualRow, 0)
+	offsets := buffer.GetLineOffsets()
+	for line, lineStart := range offsets {
+		if line < len(hiddenLines) && hiddenLines[line] {
+			continue
+		}
+
+		lineEnd := len(buffer.Contents)
+		if line+1 < len(offsets) {
+			lineEnd = offsets[line+1] - 1
+		}
+
+		rowStart := lineStart
+		lastBreak := -1
+		x := 0
+		for pos := lineStart; pos < lineEnd; {
+			r, size := utf8.DecodeRuneInString(buffer.Contents[pos:])
+
+			if x+getRuneDisplayWidth(r) > width && pos > rowStart {
+				// Wrap at the last word boundary if possible
+				end := pos
+				if Config.SoftWrapWords && lastBreak > rowStart {
+					end = lastBreak
+				}
+
+				rows = append(rows, VisualRow{Line: line, Start: rowStart, End: end, First: rowStart == lineStart})
+
+				rowStart = end
+				lastBreak = -1
+				x = 0
+				for _, r := range buffer.Contents[rowStart:pos] {
+					x += getRuneDisplayWidth(r)
+				}
+				continue
+			}
+
+			x += getRuneDisplayWidth(r)
+			pos += size
+
+			if r == ' ' || r == '\t' {
+				lastBreak = pos
+			}
+		}
+
+		rows = append(rows, VisualRow{Line: line, Start: rowStart, End: lineEnd, First: rowStart == lineStart})
+	}
+
+	buffer.visualRows = rows
+	buffer.visualRowsKey = key
+
+	return rows
+}
+
+// getVisualRowAt returns the index of the visual row containing pos
+func getVisualRowAt(rows []VisualRow, pos int) int {
+	for i, row := range rows {
+		if pos < row.Start {
+			continue
+		}
+
+		// Positions at the end of a row belong to the next row, unless it is the last row of the line
+		if pos < row.End || (pos == row.End && (i+1 == len(rows) || rows[i+1].First)) {
+			return i
+		}
+	}
+
+	return len(rows) - 1
+}
+
+// visualColumnToPos returns the position of the character at display column col of a visual row
+func (window *Window) visualColumnToPos(rows []VisualRow, row, col int) int {
+	buffer := window.CurrentBuffer
+
+	end := rows[row].End
+	if row+1 < len(rows) && !rows[row+1].First {
+		// Keep cursor on this row instead of the start of the next one
+		_, size := utf8.DecodeLastRuneInString(buffer.Contents[:end])
+		end -= size
+	}
+
+	pos := rows[row].Start
+	x := 0
+	for pos < end {
+		r, size := utf8.DecodeRuneInString(buffer.Contents[pos:])
+		if x+getRuneDisplayWidth(r) > col {
+			break
+		}
+
+		x += getRuneDisplayWidth(r)
+		pos += size
+	}
+
+	return pos
+}
+
+// GetCursorVisualPos returns the display column and visual row of the cursor
+func (window *Window) GetCursorVisualPos() (int, int) {
+	buffer := window.CurrentBuffer
+
+	rows := window.GetVisualRows()
+	row := getVisualRowAt(rows, buffer.CursorPos)
+
+	x := 0
+	for _, r := range buffer.Contents[rows[row].Start:buffer.CursorPos] {
+		x += getRuneDisplayWidth(r)
+	}
+
+	return x, row
+}
+
+func (window *Window) MoveCursorVisualRow(dy int) {
+	rows := window.GetVisualRows()
+
+	x, row := window.GetCursorVisualPos()
+	row = min(max(row+dy, 0), len(rows)-1)
+
+	window.SetCursorPos(window.visualColumnToPos(rows, row, x))
+}